@@ -1,13 +1,23 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ArtemMoroz51/FinalProject/internal/app"
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/ArtemMoroz51/FinalProject/internal/ws"
 )
 
 func main() {
+	perType, err := parseRateLimits(os.Getenv("WS_RATE_LIMITS"))
+	if err != nil {
+		panic(err)
+	}
+
 	cfg := app.Config{
 		HTTPAddr:    getenv("HTTP_ADDR", ":8080"),
 		DatabaseURL: os.Getenv("DATABASE_URL"),
@@ -19,6 +29,40 @@ func main() {
 		AnsweringSeconds: 30 * time.Second,
 		ResultsPause:     5 * time.Second,
 		MaxRounds:        5,
+
+		ReadyQuorum:    1,
+		StartCountdown: 3 * time.Second,
+
+		NameMinLen:   1,
+		NameMaxLen:   24,
+		NameConflict: getenv("NAME_CONFLICT", "reject"),
+
+		ChatMaxLen:      200,
+		ChatHistorySize: 50,
+		ChatRateLimit:   5,
+		ChatRateWindow:  10 * time.Second,
+		ChatBannedWords: splitList(os.Getenv("CHAT_BANNED_WORDS")),
+
+		MaxSpectators: game.DefaultMaxSpectators,
+
+		WSRateBurst:     20,
+		WSRatePerSecond: 10,
+
+		WSRatePerType:                    perType,
+		WSRateErrorsBeforeThrottle:       3,
+		WSRateViolationsBeforeDisconnect: 10,
+		WSRateThrottleDelay:              500 * time.Millisecond,
+		WSRateViolationWindow:            30 * time.Second,
+
+		WSAllowedOrigins:       splitList(os.Getenv("WS_ALLOWED_ORIGINS")),
+		WSCompression:          os.Getenv("WS_COMPRESSION") == "true",
+		WSCompressionThreshold: 1024,
+		WSReadBufferSize:       4096,
+		WSWriteBufferSize:      4096,
+		WSHandshakeTimeout:     10 * time.Second,
+		WSWriteWait:            10 * time.Second,
+		WSPongWait:             60 * time.Second,
+		WSMaxMessageSize:       8 * 1024,
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -43,3 +87,33 @@ func getenv(k, def string) string {
 	}
 	return v
 }
+
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func parseRateLimits(v string) (map[string]ws.Limit, error) {
+	out := make(map[string]ws.Limit)
+	for _, item := range splitList(v) {
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("bad WS_RATE_LIMITS entry %q, want type:burst:perSecond", item)
+		}
+		burst, err := strconv.Atoi(parts[1])
+		if err != nil || burst <= 0 {
+			return nil, fmt.Errorf("bad burst in WS_RATE_LIMITS entry %q", item)
+		}
+		perSecond, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || perSecond < 0 {
+			return nil, fmt.Errorf("bad rate in WS_RATE_LIMITS entry %q", item)
+		}
+		out[strings.TrimSpace(parts[0])] = ws.Limit{Burst: burst, PerSecond: perSecond}
+	}
+	return out, nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/ws"
+)
+
+func main() {
+	doc, err := ws.JSONSchema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 {
+		if err := os.WriteFile(os.Args[1], append(doc, '\n'), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Println(string(doc))
+}
@@ -44,14 +44,68 @@ func New(cfg Config) (*App, error) {
 		AnsweringSeconds: cfg.AnsweringSeconds,
 		ResultsPause:     cfg.ResultsPause,
 		MaxRounds:        cfg.MaxRounds,
+
+		ReadyQuorum:    cfg.ReadyQuorum,
+		StartCountdown: cfg.StartCountdown,
+
+		NameRules: game.NameRules{
+			MinLen:   cfg.NameMinLen,
+			MaxLen:   cfg.NameMaxLen,
+			Conflict: game.NameConflictPolicy(cfg.NameConflict),
+		},
+		ChatRules: game.ChatRules{
+			MaxLen:      cfg.ChatMaxLen,
+			HistorySize: cfg.ChatHistorySize,
+			RateLimit:   cfg.ChatRateLimit,
+			RateWindow:  cfg.ChatRateWindow,
+			BannedWords: cfg.ChatBannedWords,
+		},
+
+		MaxSpectators: cfg.MaxSpectators,
 	})
 	adminSvc := service.NewAdminService(qs)
 
-	hub := ws.NewHub(gameSvc, l)
+	limits := ws.DefaultRateLimits()
+	if cfg.WSRateBurst > 0 {
+		limits.Connection = ws.Limit{Burst: cfg.WSRateBurst, PerSecond: cfg.WSRatePerSecond}
+	}
+	for msgType, limit := range cfg.WSRatePerType {
+		limits.PerType[msgType] = limit
+	}
+	if cfg.WSRateErrorsBeforeThrottle > 0 {
+		limits.ErrorsBeforeThrottle = cfg.WSRateErrorsBeforeThrottle
+	}
+	if cfg.WSRateViolationsBeforeDisconnect > 0 {
+		limits.ViolationsBeforeDisconnect = cfg.WSRateViolationsBeforeDisconnect
+	}
+	if cfg.WSRateThrottleDelay > 0 {
+		limits.ThrottleDelay = cfg.WSRateThrottleDelay
+	}
+	if cfg.WSRateViolationWindow > 0 {
+		limits.ViolationWindow = cfg.WSRateViolationWindow
+	}
+	hub := ws.NewHub(gameSvc, l, ws.Config{
+		AllowedOrigins: cfg.WSAllowedOrigins,
+
+		EnableCompression:    cfg.WSCompression,
+		CompressionThreshold: cfg.WSCompressionThreshold,
+
+		ReadBufferSize:   cfg.WSReadBufferSize,
+		WriteBufferSize:  cfg.WSWriteBufferSize,
+		HandshakeTimeout: cfg.WSHandshakeTimeout,
+
+		WriteWait:      cfg.WSWriteWait,
+		PongWait:       cfg.WSPongWait,
+		MaxMessageSize: cfg.WSMaxMessageSize,
+
+		RateLimits: limits,
+	})
+	gameSvc.SetNotifier(hub)
 
 	mux := http.NewServeMux()
 	handler.RegisterHandlers(mux, gameSvc, hub, l)
 	handler.RegisterAdminHandlers(mux, adminSvc, cfg.AdminToken, l)
+	handler.RegisterAdminWSHandlers(mux, hub, cfg.AdminToken, l)
 
 	srv := &http.Server{
 		Addr:    cfg.HTTPAddr,
@@ -1,6 +1,10 @@
 package app
 
-import "time"
+import (
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/ws"
+)
 
 type Config struct {
 	HTTPAddr    string
@@ -13,4 +17,38 @@ type Config struct {
 	AnsweringSeconds time.Duration
 	ResultsPause     time.Duration
 	MaxRounds        int
+
+	ReadyQuorum    float64
+	StartCountdown time.Duration
+
+	NameMinLen   int
+	NameMaxLen   int
+	NameConflict string
+
+	ChatMaxLen      int
+	ChatHistorySize int
+	ChatRateLimit   int
+	ChatRateWindow  time.Duration
+	ChatBannedWords []string
+
+	MaxSpectators int
+
+	WSRateBurst     int
+	WSRatePerSecond float64
+
+	WSRatePerType                    map[string]ws.Limit
+	WSRateErrorsBeforeThrottle       int
+	WSRateViolationsBeforeDisconnect int
+	WSRateThrottleDelay              time.Duration
+	WSRateViolationWindow            time.Duration
+
+	WSAllowedOrigins       []string
+	WSCompression          bool
+	WSCompressionThreshold int
+	WSReadBufferSize       int
+	WSWriteBufferSize      int
+	WSHandshakeTimeout     time.Duration
+	WSWriteWait            time.Duration
+	WSPongWait             time.Duration
+	WSMaxMessageSize       int64
 }
@@ -0,0 +1,47 @@
+package game
+
+var AvatarIDs = []string{
+	"cat", "dog", "fox", "owl", "bear", "frog",
+	"panda", "tiger", "rabbit", "penguin", "koala", "octopus",
+}
+
+var Colors = []string{
+	"#E53935", "#1E88E5", "#43A047", "#FDD835", "#8E24AA", "#FB8C00",
+	"#00ACC1", "#D81B60", "#6D4C41", "#3949AB", "#7CB342", "#546E7A",
+}
+
+func (r *Room) assignLookLocked(p *Player) {
+	p.Avatar = pickFree(AvatarIDs, p.Avatar, r.usedLocked(p.ID, func(o *Player) string { return o.Avatar }), r.joinSeq)
+	p.Color = pickFree(Colors, p.Color, r.usedLocked(p.ID, func(o *Player) string { return o.Color }), r.joinSeq)
+}
+
+func (r *Room) usedLocked(exceptID string, field func(*Player) string) map[string]bool {
+	used := make(map[string]bool, len(r.Players))
+	for id, o := range r.Players {
+		if id != exceptID {
+			used[field(o)] = true
+		}
+	}
+	return used
+}
+
+func pickFree(set []string, wanted string, used map[string]bool, seq int) string {
+	if contains(set, wanted) && !used[wanted] {
+		return wanted
+	}
+	for _, v := range set {
+		if !used[v] {
+			return v
+		}
+	}
+	return set[seq%len(set)]
+}
+
+func contains(set []string, v string) bool {
+	for _, s := range set {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
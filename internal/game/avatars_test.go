@@ -0,0 +1,49 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoom_AddPlayer_AssignsRequestedLook(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.Equal(t, AvatarIDs[0], host.Avatar)
+	require.Equal(t, Colors[0], host.Color)
+
+	p2 := &Player{ID: "p2", Name: "P2", Avatar: "owl", Color: "#43A047"}
+	r.AddPlayer(p2)
+	require.Equal(t, "owl", p2.Avatar)
+	require.Equal(t, "#43A047", p2.Color)
+}
+
+func TestRoom_AddPlayer_ReplacesTakenOrUnknownLook(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+
+	p2 := &Player{ID: "p2", Name: "P2", Avatar: host.Avatar, Color: "#000000"}
+	r.AddPlayer(p2)
+	require.Equal(t, AvatarIDs[1], p2.Avatar)
+	require.Equal(t, Colors[1], p2.Color)
+}
+
+func TestRoom_AddPlayer_LookUniqueUntilSetExhausted(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+	for i := 2; i <= len(AvatarIDs)+1; i++ {
+		r.AddPlayer(&Player{ID: string(rune('a' + i)), Name: "P"})
+	}
+
+	seen := make(map[string]int)
+	for _, p := range r.Players {
+		seen[p.Avatar]++
+	}
+	require.Len(t, seen, len(AvatarIDs))
+}
+
+func TestBuildLeaderboard_IncludesLook(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+
+	payload := BuildLeaderboard(r.Snapshot())
+	require.Len(t, payload.Leaderboard, 1)
+	require.Equal(t, host.Avatar, payload.Leaderboard[0].Avatar)
+	require.Equal(t, host.Color, payload.Leaderboard[0].Color)
+}
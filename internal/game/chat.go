@@ -0,0 +1,175 @@
+package game
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type ChatRules struct {
+	MaxLen      int
+	HistorySize int
+	RateLimit   int
+	RateWindow  time.Duration
+	BannedWords []string
+}
+
+func DefaultChatRules() ChatRules {
+	return ChatRules{MaxLen: 200, HistorySize: 50, RateLimit: 5, RateWindow: 10 * time.Second}
+}
+
+type ChatMessage struct {
+	ID       uint64 `json:"id"`
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Avatar   string `json:"avatar"`
+	Color    string `json:"color"`
+	Text     string `json:"text"`
+	SentAt   int64  `json:"sentAt"`
+}
+
+func (cr ChatRules) Normalize(text string) (string, error) {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, norm.NFC.String(text))
+	text = strings.Join(strings.Fields(text), " ")
+
+	if text == "" {
+		return "", ErrChatEmpty
+	}
+	if cr.MaxLen > 0 && utf8.RuneCountInString(text) > cr.MaxLen {
+		return "", ErrChatTooLong
+	}
+	return cr.filter(text), nil
+}
+
+func (cr ChatRules) filter(text string) string {
+	if len(cr.BannedWords) == 0 {
+		return text
+	}
+	banned := make(map[string]struct{}, len(cr.BannedWords))
+	for _, w := range cr.BannedWords {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			banned[w] = struct{}{}
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isWord(r) {
+			b.WriteRune(r)
+			i += size
+			continue
+		}
+
+		j := i
+		for j < len(text) {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if !isWord(r) {
+				break
+			}
+			j += size
+		}
+
+		word := text[i:j]
+		if _, ok := banned[strings.ToLower(word)]; ok {
+			b.WriteString(strings.Repeat("*", utf8.RuneCountInString(word)))
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func (r *Room) PostChat(playerID, text string, rules ChatRules, now time.Time) (ChatMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.Players[playerID]
+	if !ok {
+		return ChatMessage{}, ErrPlayerNotFound
+	}
+	if r.Phase == PhaseClosed {
+		return ChatMessage{}, ErrBadPhase
+	}
+	if p.Muted {
+		return ChatMessage{}, ErrChatMuted
+	}
+	if r.Phase == PhaseAnswering && !r.Settings.ChatDuringAnswering {
+		return ChatMessage{}, ErrChatDisabled
+	}
+
+	if rules.RateLimit > 0 {
+		if r.chatSent == nil {
+			r.chatSent = make(map[string][]time.Time)
+		}
+		recent := r.chatSent[playerID][:0]
+		for _, t := range r.chatSent[playerID] {
+			if now.Sub(t) < rules.RateWindow {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) >= rules.RateLimit {
+			r.chatSent[playerID] = recent
+			return ChatMessage{}, ErrChatRateLimited
+		}
+		r.chatSent[playerID] = append(recent, now)
+	}
+
+	r.chatSeq++
+	msg := ChatMessage{
+		ID:       r.chatSeq,
+		PlayerID: p.ID,
+		Name:     p.Name,
+		Avatar:   p.Avatar,
+		Color:    p.Color,
+		Text:     text,
+		SentAt:   now.UnixMilli(),
+	}
+
+	r.chat = append(r.chat, msg)
+	if rules.HistorySize > 0 && len(r.chat) > rules.HistorySize {
+		r.chat = append([]ChatMessage(nil), r.chat[len(r.chat)-rules.HistorySize:]...)
+	}
+	return msg, nil
+}
+
+func (r *Room) ChatHistory() []ChatMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]ChatMessage{}, r.chat...)
+}
+
+type ChatMutedPayload struct {
+	Muted bool `json:"muted"`
+}
+
+func (r *Room) MutePlayer(requesterID, targetID string, muted bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return ErrNotHost
+	}
+	p, ok := r.Players[targetID]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+	p.Muted = muted
+	return nil
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatRules_Normalize(t *testing.T) {
+	rules := ChatRules{MaxLen: 10}
+
+	text, err := rules.Normalize("  hi\n\tthere ​ ")
+	require.NoError(t, err)
+	require.Equal(t, "hi there", text)
+
+	_, err = rules.Normalize(" \n ")
+	require.ErrorIs(t, err, ErrChatEmpty)
+
+	_, err = rules.Normalize("01234567890")
+	require.ErrorIs(t, err, ErrChatTooLong)
+}
+
+func TestChatRules_WordFilter(t *testing.T) {
+	rules := ChatRules{MaxLen: 100, BannedWords: []string{"Darn", "heck"}}
+
+	text, err := rules.Normalize("DARN it, what the heck! darned hecks")
+	require.NoError(t, err)
+	require.Equal(t, "**** it, what the ****! darned hecks", text)
+}
+
+func TestRoom_PostChat_HistoryBounded(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	rules := ChatRules{HistorySize: 2}
+	now := time.Now()
+
+	for _, text := range []string{"one", "two", "three"} {
+		_, err := r.PostChat(host.ID, text, rules, now)
+		require.NoError(t, err)
+	}
+
+	history := r.ChatHistory()
+	require.Len(t, history, 2)
+	require.Equal(t, "two", history[0].Text)
+	require.Equal(t, "three", history[1].Text)
+	require.Equal(t, uint64(3), history[1].ID)
+	require.Equal(t, host.Name, history[1].Name)
+}
+
+func TestRoom_PostChat_RateLimit(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	rules := ChatRules{RateLimit: 2, RateWindow: 10 * time.Second}
+	now := time.Now()
+
+	_, err := r.PostChat(host.ID, "a", rules, now)
+	require.NoError(t, err)
+	_, err = r.PostChat(host.ID, "b", rules, now.Add(time.Second))
+	require.NoError(t, err)
+	_, err = r.PostChat(host.ID, "c", rules, now.Add(2*time.Second))
+	require.ErrorIs(t, err, ErrChatRateLimited)
+
+	_, err = r.PostChat(host.ID, "d", rules, now.Add(11*time.Second))
+	require.NoError(t, err)
+}
+
+func TestRoom_PostChat_RateLimitForgottenOnLeave(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+	rules := ChatRules{RateLimit: 1, RateWindow: 10 * time.Second}
+
+	_, err := r.PostChat("p2", "hi", rules, time.Now())
+	require.NoError(t, err)
+	require.Contains(t, r.chatSent, "p2")
+
+	r.RemovePlayer("p2")
+	require.NotContains(t, r.chatSent, "p2")
+}
+
+func TestRoom_PostChat_DisabledWhileAnswering(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	_, err := r.PostChat(host.ID, "it's B", ChatRules{}, time.Now())
+	require.ErrorIs(t, err, ErrChatDisabled)
+
+	r.Settings.ChatDuringAnswering = true
+	_, err = r.PostChat(host.ID, "it's B", ChatRules{}, time.Now())
+	require.NoError(t, err)
+}
+
+func TestRoom_MutePlayer(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+
+	require.ErrorIs(t, r.MutePlayer("p2", host.ID, true), ErrNotHost)
+	require.ErrorIs(t, r.MutePlayer(host.ID, "ghost", true), ErrPlayerNotFound)
+
+	require.NoError(t, r.MutePlayer(host.ID, "p2", true))
+	_, err := r.PostChat("p2", "hello", ChatRules{}, time.Now())
+	require.ErrorIs(t, err, ErrChatMuted)
+	require.True(t, r.Players["p2"].Muted)
+
+	require.NoError(t, r.MutePlayer(host.ID, "p2", false))
+	_, err = r.PostChat("p2", "hello", ChatRules{}, time.Now())
+	require.NoError(t, err)
+}
@@ -0,0 +1,59 @@
+package game
+
+func (r *Room) AddDisplay(id, hostToken string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.hostTokenValidLocked(hostToken) {
+		return ErrBadHostToken
+	}
+	if r.Phase == PhaseClosed {
+		return ErrBadPhase
+	}
+	if r.displays == nil {
+		r.displays = make(map[string]struct{})
+	}
+	r.displays[id] = struct{}{}
+	return nil
+}
+
+func (r *Room) RemoveDisplay(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.displays[id]; !ok {
+		return false
+	}
+	delete(r.displays, id)
+	return r.Phase != PhaseClosed
+}
+
+func (r *Room) IsDisplay(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.displays[id]
+	return ok
+}
+
+func (r *Room) CanControl(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.canControlLocked(id)
+}
+
+func (r *Room) canControlLocked(id string) bool {
+	if id == "" {
+		return false
+	}
+	if id == r.HostID {
+		return true
+	}
+	_, ok := r.displays[id]
+	return ok
+}
+
+func (r *Room) displayMissingLocked() bool {
+	return r.Settings.RequireDisplay && len(r.displays) == 0
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoom_Display_NotPlayerButCanControl(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.HostToken = "secret"
+	require.NoError(t, r.AddDisplay("tv", "secret"))
+
+	snap := r.Snapshot()
+	require.Equal(t, 1, snap.Displays)
+	require.Len(t, snap.Players, 1)
+	require.Equal(t, host.ID, snap.HostID)
+
+	require.True(t, r.IsDisplay("tv"))
+	require.True(t, r.CanControl("tv"))
+	require.True(t, r.CanControl(host.ID))
+	require.False(t, r.CanControl("stranger"))
+	require.False(t, r.CanControl(""))
+
+	require.NoError(t, r.UpdateSettings("tv", RoomSettings{HostPaced: true}))
+	require.NoError(t, r.StartGame("tv", validQuestion(), 30))
+
+	_, _, err := r.SetReady("tv", true)
+	require.ErrorIs(t, err, ErrBadPhase)
+	require.ErrorIs(t, r.SubmitAnswer("tv", "B"), ErrPlayerNotFound)
+
+	require.True(t, r.RemoveDisplay("tv"))
+	require.False(t, r.RemoveDisplay("tv"))
+	require.False(t, r.CanControl("tv"))
+}
+
+func TestRoom_RequireDisplay_BlocksStart(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.Settings.RequireDisplay = true
+
+	require.ErrorIs(t, r.StartGame(host.ID, validQuestion(), 30), ErrDisplayRequired)
+	require.ErrorIs(t, r.StartCountdown(time.Second), ErrDisplayRequired)
+
+	r.HostToken = "secret"
+	require.NoError(t, r.AddDisplay("tv", "secret"))
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+}
+
+func TestRoom_Display_NeverBecomesHost(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.HostToken = "secret"
+	require.NoError(t, r.AddDisplay("tv", "secret"))
+
+	newHostID, changed := r.RemovePlayer(host.ID)
+	require.True(t, changed)
+	require.Empty(t, newHostID)
+
+	r.AddPlayer(&Player{ID: "p2", Name: "Second"})
+	require.Equal(t, "p2", r.Snapshot().HostID)
+}
+
+func TestRoom_AddDisplay_RequiresHostToken(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+
+	require.ErrorIs(t, r.AddDisplay("tv", ""), ErrBadHostToken)
+
+	r.HostToken = "secret"
+	require.ErrorIs(t, r.AddDisplay("tv", ""), ErrBadHostToken)
+	require.ErrorIs(t, r.AddDisplay("tv", "guess"), ErrBadHostToken)
+	require.False(t, r.IsDisplay("tv"))
+	require.False(t, r.CanControl("tv"))
+	require.Zero(t, r.Snapshot().Displays)
+}
@@ -11,4 +11,25 @@ var (
 	ErrEmptyAnswer     = errors.New("empty answer")
 	ErrInvalidOption   = errors.New("invalid option")
 	ErrInvalidQuestion = errors.New("invalid question")
+	ErrPlayerNotFound  = errors.New("player not found")
+	ErrPlayerOffline   = errors.New("player offline")
+	ErrGameNotFinished = errors.New("game not finished")
+	ErrPaused          = errors.New("game paused")
+	ErrNotPaused       = errors.New("game not paused")
+	ErrNotHostPaced    = errors.New("room is not host-paced")
+	ErrInvalidToken    = errors.New("invalid resume token")
+	ErrNameInvalid     = errors.New("invalid name")
+	ErrNameTooShort    = errors.New("name too short")
+	ErrNameTooLong     = errors.New("name too long")
+	ErrNameTaken       = errors.New("name taken")
+	ErrRoomNotFound    = errors.New("room not found")
+	ErrBadHostToken    = errors.New("bad host token")
+	ErrChatEmpty       = errors.New("empty chat message")
+	ErrChatTooLong     = errors.New("chat message too long")
+	ErrChatMuted       = errors.New("player is muted")
+	ErrChatDisabled    = errors.New("chat disabled while answering")
+	ErrChatRateLimited = errors.New("chat rate limited")
+	ErrDisplayRequired = errors.New("display required")
+	ErrSpectator       = errors.New("spectators cannot play")
+	ErrSpectatorsFull  = errors.New("spectator limit reached")
 )
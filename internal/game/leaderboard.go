@@ -0,0 +1,69 @@
+package game
+
+import "sort"
+
+type LeaderboardEntry struct {
+	Place    int    `json:"place"`
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Avatar   string `json:"avatar"`
+	Color    string `json:"color"`
+	Score    int    `json:"score"`
+}
+
+type GameOverPayload struct {
+	Code         string             `json:"code"`
+	RoundsPlayed int                `json:"roundsPlayed"`
+	Leaderboard  []LeaderboardEntry `json:"leaderboard"`
+}
+
+func BuildLeaderboard(snap RoomSnapshot) GameOverPayload {
+	type row struct {
+		id     string
+		name   string
+		avatar string
+		color  string
+		score  int
+	}
+	rows := make([]row, 0, len(snap.Players))
+	for _, p := range snap.Players {
+		rows = append(rows, row{
+			id:     p.ID,
+			name:   p.Name,
+			avatar: p.Avatar,
+			color:  p.Color,
+			score:  snap.Scores[p.ID],
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].score != rows[j].score {
+			return rows[i].score > rows[j].score
+		}
+		return rows[i].name < rows[j].name
+	})
+
+	leaderboard := make([]LeaderboardEntry, 0, len(rows))
+	place := 0
+	prevScore := -1
+	for i, r := range rows {
+		if i == 0 || r.score != prevScore {
+			place = i + 1
+			prevScore = r.score
+		}
+		leaderboard = append(leaderboard, LeaderboardEntry{
+			Place:    place,
+			PlayerID: r.id,
+			Name:     r.name,
+			Avatar:   r.avatar,
+			Color:    r.color,
+			Score:    r.score,
+		})
+	}
+
+	return GameOverPayload{
+		Code:         snap.Code,
+		RoundsPlayed: snap.RoundNumber,
+		Leaderboard:  leaderboard,
+	}
+}
@@ -4,11 +4,33 @@ type Phase string
 
 const (
 	PhaseLobby     Phase = "lobby"
+	PhaseCountdown Phase = "countdown"
 	PhaseAnswering Phase = "answering"
 	PhaseResults   Phase = "results"
+	PhaseClosed    Phase = "closed"
 )
 
 type Player struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	JoinOrder int    `json:"joinOrder"`
+	Ready     bool   `json:"ready"`
+	Online    bool   `json:"online"`
+	Avatar    string `json:"avatar"`
+	Color     string `json:"color"`
+	Muted     bool   `json:"muted,omitempty"`
+
+	ResumeToken string `json:"-"`
+	Session     int    `json:"-"`
+}
+
+type RoomSettings struct {
+	EndRoundEarly       bool `json:"endRoundEarly"`
+	HostPaced           bool `json:"hostPaced"`
+	ChatDuringAnswering bool `json:"chatDuringAnswering"`
+	RequireDisplay      bool `json:"requireDisplay"`
+}
+
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{EndRoundEarly: true, ChatDuringAnswering: true}
 }
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type NameConflictPolicy string
+
+const (
+	NameConflictReject     NameConflictPolicy = "reject"
+	NameConflictAutoSuffix NameConflictPolicy = "suffix"
+)
+
+type NameRules struct {
+	MinLen   int
+	MaxLen   int
+	Conflict NameConflictPolicy
+}
+
+func DefaultNameRules() NameRules {
+	return NameRules{MinLen: 1, MaxLen: 24, Conflict: NameConflictReject}
+}
+
+func (nr NameRules) Normalize(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", ErrNameInvalid
+	}
+	name = strings.TrimSpace(norm.NFC.String(name))
+
+	for _, r := range name {
+		if r == ' ' {
+			continue
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || !unicode.IsGraphic(r) || unicode.IsSpace(r) {
+			return "", ErrNameInvalid
+		}
+	}
+	name = strings.Join(strings.Fields(name), " ")
+
+	n := utf8.RuneCountInString(name)
+	if n == 0 || n < nr.MinLen {
+		return "", ErrNameTooShort
+	}
+	if nr.MaxLen > 0 && n > nr.MaxLen {
+		return "", ErrNameTooLong
+	}
+	return name, nil
+}
+
+func (r *Room) JoinPlayer(p *Player, rules NameRules) (joined *Player, isHost bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTakenLocked(p.Name, p.ID) {
+		if rules.Conflict != NameConflictAutoSuffix {
+			return nil, false, ErrNameTaken
+		}
+		p.Name = r.suggestNameLocked(p.Name, rules.MaxLen)
+	}
+	isHost = r.addPlayerLocked(p)
+	cp := *p
+	return &cp, isHost, nil
+}
+
+func (r *Room) SuggestName(name string, rules NameRules) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.suggestNameLocked(name, rules.MaxLen)
+}
+
+func (r *Room) nameTakenLocked(name, exceptID string) bool {
+	for id, p := range r.Players {
+		if id != exceptID && strings.EqualFold(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Room) suggestNameLocked(name string, maxLen int) string {
+	if !r.nameTakenLocked(name, "") {
+		return name
+	}
+	for i := 2; ; i++ {
+		suffix := " " + strconv.Itoa(i)
+		candidate := truncateName(name, maxLen-utf8.RuneCountInString(suffix)) + suffix
+		if !r.nameTakenLocked(candidate, "") {
+			return candidate
+		}
+	}
+}
+
+func truncateName(name string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(name) <= maxLen {
+		return name
+	}
+	return strings.TrimRight(string([]rune(name)[:maxLen]), " ")
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameRules_Normalize_Success(t *testing.T) {
+	nr := DefaultNameRules()
+
+	name, err := nr.Normalize("  Alex   the  Great ")
+	require.NoError(t, err)
+	require.Equal(t, "Alex the Great", name)
+
+	name, err = nr.Normalize("Zoe\u0301")
+	require.NoError(t, err)
+	require.Equal(t, "Zo\u00e9", name)
+}
+
+func TestNameRules_Normalize_Rejects(t *testing.T) {
+	nr := NameRules{MinLen: 2, MaxLen: 5}
+
+	_, err := nr.Normalize("   ")
+	require.ErrorIs(t, err, ErrNameTooShort)
+
+	_, err = nr.Normalize("A")
+	require.ErrorIs(t, err, ErrNameTooShort)
+
+	_, err = nr.Normalize(strings.Repeat("a", 6))
+	require.ErrorIs(t, err, ErrNameTooLong)
+
+	_, err = nr.Normalize("Al\u200bex")
+	require.ErrorIs(t, err, ErrNameInvalid)
+
+	_, err = nr.Normalize("Al\nex")
+	require.ErrorIs(t, err, ErrNameInvalid)
+
+	_, err = nr.Normalize("Al\x00")
+	require.ErrorIs(t, err, ErrNameInvalid)
+
+	_, err = nr.Normalize("\xff\xfe")
+	require.ErrorIs(t, err, ErrNameInvalid)
+}
+
+func TestRoom_JoinPlayer_RejectsDuplicateCaseInsensitive(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+
+	_, _, err := r.JoinPlayer(&Player{ID: "p2", Name: "host"}, DefaultNameRules())
+	require.ErrorIs(t, err, ErrNameTaken)
+	require.Len(t, r.Players, 1)
+
+	require.Equal(t, "host 2", r.SuggestName("host", DefaultNameRules()))
+}
+
+func TestRoom_JoinPlayer_AutoSuffix(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+	rules := NameRules{MinLen: 1, MaxLen: 24, Conflict: NameConflictAutoSuffix}
+
+	p2, isHost, err := r.JoinPlayer(&Player{ID: "p2", Name: "HOST"}, rules)
+	require.NoError(t, err)
+	require.False(t, isHost)
+	require.Equal(t, "HOST 2", p2.Name)
+
+	p3, _, err := r.JoinPlayer(&Player{ID: "p3", Name: "Host"}, rules)
+	require.NoError(t, err)
+	require.Equal(t, "Host 3", p3.Name)
+
+	p3.Ready = true
+	require.False(t, r.Players["p3"].Ready)
+}
+
+func TestRoom_SuggestName_FitsMaxLen(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+	rules := NameRules{MinLen: 1, MaxLen: 24, Conflict: NameConflictAutoSuffix}
+
+	long := strings.Repeat("Ж", 24)
+	_, _, err := r.JoinPlayer(&Player{ID: "p2", Name: long}, rules)
+	require.NoError(t, err)
+
+	p3, _, err := r.JoinPlayer(&Player{ID: "p3", Name: long}, rules)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("Ж", 22)+" 2", p3.Name)
+	require.Equal(t, 24, utf8.RuneCountInString(p3.Name))
+
+	r.AddPlayer(&Player{ID: "p4", Name: "Team Rocket A"})
+	require.Equal(t, "Team Rocket 2", r.SuggestName("Team Rocket A", NameRules{MaxLen: 13}))
+}
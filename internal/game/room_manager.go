@@ -2,7 +2,10 @@ package game
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base32"
+	"encoding/hex"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -24,16 +27,33 @@ type Room struct {
 	Phase   Phase
 	Players map[string]*Player
 
-	HostID string
+	HostID    string
+	HostToken string
+
+	Settings RoomSettings
 
 	RoundNumber     int
 	CurrentQuestion Question
 
 	AnsweringDeadline time.Time
+	CountdownDeadline time.Time
+
+	Paused          bool
+	PausedRemaining time.Duration
 
 	Answers map[string]string
 	Scores  map[string]int
 
+	PreviousGame *GameOverPayload
+
+	joinSeq    int
+	displays   map[string]struct{}
+	spectators map[string]struct{}
+
+	chat     []ChatMessage
+	chatSeq  uint64
+	chatSent map[string][]time.Time
+
 	mu sync.Mutex
 }
 
@@ -43,18 +63,43 @@ type RoomSnapshot struct {
 	HostID      string `json:"hostId"`
 	RoundNumber int    `json:"roundNumber"`
 
+	Settings RoomSettings `json:"settings"`
+
 	Question string   `json:"question,omitempty"`
 	Options  []Option `json:"options,omitempty"`
 
-	Deadline int64          `json:"deadline,omitempty"`
-	Players  []*Player      `json:"players"`
-	Scores   map[string]int `json:"scores"`
+	Deadline    int64          `json:"deadline,omitempty"`
+	RemainingMs int64          `json:"remainingMs,omitempty"`
+	Players     []*Player      `json:"players"`
+	Scores      map[string]int `json:"scores"`
+
+	Answered map[string]bool `json:"answered,omitempty"`
+
+	Paused            bool  `json:"paused"`
+	PausedRemainingMs int64 `json:"pausedRemainingMs,omitempty"`
+
+	Displays   int `json:"displays"`
+	Spectators int `json:"spectators"`
+
+	PreviousGame *GameOverPayload `json:"previousGame,omitempty"`
 }
 
 func (r *Room) AddPlayer(p *Player) (isHost bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.addPlayerLocked(p)
+}
+
+func (r *Room) addPlayerLocked(p *Player) (isHost bool) {
+	if existing, ok := r.Players[p.ID]; ok {
+		p.JoinOrder = existing.JoinOrder
+	} else {
+		r.joinSeq++
+		p.JoinOrder = r.joinSeq
+	}
+	r.assignLookLocked(p)
+	p.Online = true
 	r.Players[p.ID] = p
 
 	if r.Scores != nil {
@@ -70,32 +115,127 @@ func (r *Room) AddPlayer(p *Player) (isHost bool) {
 	return false
 }
 
-func (r *Room) RemovePlayer(playerID string) {
+func (r *Room) RemovePlayer(playerID string) (newHostID string, hostChanged bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	delete(r.Players, playerID)
+	return r.removePlayerLocked(playerID)
+}
 
-	if r.HostID == playerID {
-		r.HostID = ""
-		for id := range r.Players {
-			r.HostID = id
-			break
+func (r *Room) ResumePlayer(token string) (*Player, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	for _, p := range r.Players {
+		if p.ResumeToken == token {
+			p.Session++
+			p.Online = true
+			cp := *p
+			return &cp, nil
 		}
 	}
+	return nil, ErrInvalidToken
+}
+
+func (r *Room) DisconnectPlayer(playerID string, session int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.Players[playerID]
+	if !ok || p.Session != session || !p.Online {
+		return false
+	}
+	p.Online = false
+	p.Ready = false
+	return r.Phase != PhaseClosed
+}
+
+func (r *Room) RemoveIfOffline(playerID string, session int) (newHostID string, hostChanged, removed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.Players[playerID]
+	if !ok || p.Session != session || p.Online {
+		return r.HostID, false, false
+	}
+	newHostID, hostChanged = r.removePlayerLocked(playerID)
+	return newHostID, hostChanged, true
+}
+
+func (r *Room) PendingAnswer(playerID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Phase != PhaseAnswering {
+		return ""
+	}
+	return r.Answers[playerID]
+}
+
+func (r *Room) removePlayerLocked(playerID string) (newHostID string, hostChanged bool) {
+	delete(r.Players, playerID)
+	delete(r.chatSent, playerID)
+
+	if r.HostID != playerID {
+		return r.HostID, false
+	}
+
+	r.HostID = ""
+	if next := r.longestConnectedLocked(); next != nil {
+		r.HostID = next.ID
+	}
+	return r.HostID, true
 }
 
+func (r *Room) TransferHost(requesterID, targetID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return ErrNotHost
+	}
+	target, ok := r.Players[targetID]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+	if !target.Online {
+		return ErrPlayerOffline
+	}
+
+	r.HostID = targetID
+	return nil
+}
+
+func (r *Room) longestConnectedLocked() *Player {
+	var next *Player
+	for _, p := range r.Players {
+		if next == nil || (p.Online && !next.Online) ||
+			(p.Online == next.Online && p.JoinOrder < next.JoinOrder) {
+			next = p
+		}
+	}
+	return next
+}
 
 func (r *Room) StartGame(requesterID string, q Question, answeringSeconds int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if r.HostID == "" || r.HostID != requesterID {
+	if !r.canControlLocked(requesterID) {
 		return ErrNotHost
 	}
-	if r.Phase != PhaseLobby && r.Phase != PhaseResults {
+	if r.Phase != PhaseLobby && r.Phase != PhaseCountdown && r.Phase != PhaseResults {
 		return ErrBadPhase
 	}
+	if r.Paused {
+		return ErrPaused
+	}
+	if r.Phase != PhaseResults && r.displayMissingLocked() {
+		return ErrDisplayRequired
+	}
 	if len(r.Players) < 1 {
 		return ErrNoPlayers
 	}
@@ -121,11 +261,87 @@ func (r *Room) StartGame(requesterID string, q Question, answeringSeconds int) e
 		}
 	}
 
+	for _, p := range r.Players {
+		p.Ready = false
+	}
+	r.CountdownDeadline = time.Time{}
+
 	r.Phase = PhaseAnswering
 	r.AnsweringDeadline = time.Now().Add(time.Duration(answeringSeconds) * time.Second)
 	return nil
 }
 
+func (r *Room) SetReady(playerID string, ready bool) (readyCount, total int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Phase != PhaseLobby && r.Phase != PhaseCountdown {
+		return 0, 0, ErrBadPhase
+	}
+	p, ok := r.Players[playerID]
+	if !ok {
+		return 0, 0, ErrPlayerNotFound
+	}
+	p.Ready = ready
+
+	if !ready && r.Phase == PhaseCountdown {
+		r.Phase = PhaseLobby
+		r.CountdownDeadline = time.Time{}
+	}
+
+	readyCount, total = r.readyCountLocked()
+	return readyCount, total, nil
+}
+
+func (r *Room) CheckReadyQuorum(quorum float64, d time.Duration) (started, cancelled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	readyCount, total := r.readyCountLocked()
+	reached := total > 0 && float64(readyCount) >= quorum*float64(total)
+
+	switch {
+	case reached && r.Phase == PhaseLobby && !r.displayMissingLocked():
+		r.Phase = PhaseCountdown
+		r.CountdownDeadline = time.Now().Add(d)
+		return true, false
+	case !reached && r.Phase == PhaseCountdown:
+		r.Phase = PhaseLobby
+		r.CountdownDeadline = time.Time{}
+		return false, true
+	}
+	return false, false
+}
+
+func (r *Room) readyCountLocked() (readyCount, total int) {
+	for _, p := range r.Players {
+		if !p.Online {
+			continue
+		}
+		total++
+		if p.Ready {
+			readyCount++
+		}
+	}
+	return readyCount, total
+}
+
+func (r *Room) StartCountdown(d time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Phase != PhaseLobby {
+		return ErrBadPhase
+	}
+	if r.displayMissingLocked() {
+		return ErrDisplayRequired
+	}
+
+	r.Phase = PhaseCountdown
+	r.CountdownDeadline = time.Now().Add(d)
+	return nil
+}
+
 func (r *Room) SubmitAnswer(playerID string, optionID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -133,9 +349,15 @@ func (r *Room) SubmitAnswer(playerID string, optionID string) error {
 	if r.Phase != PhaseAnswering {
 		return ErrBadPhase
 	}
+	if r.Paused {
+		return ErrPaused
+	}
 	if !r.AnsweringDeadline.IsZero() && time.Now().After(r.AnsweringDeadline) {
 		return ErrDeadlinePassed
 	}
+	if _, ok := r.Players[playerID]; !ok {
+		return ErrPlayerNotFound
+	}
 
 	optionID = strings.TrimSpace(optionID)
 	if optionID == "" {
@@ -156,9 +378,40 @@ func (r *Room) SubmitAnswer(playerID string, optionID string) error {
 	return nil
 }
 
+type AnswerProgress struct {
+	Answered  int      `json:"answered"`
+	Total     int      `json:"total"`
+	PlayerIDs []string `json:"playerIds"`
+}
+
+func (r *Room) AnswerProgress() AnswerProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.answerProgressLocked()
+}
+
+func (r *Room) answerProgressLocked() AnswerProgress {
+	progress := AnswerProgress{PlayerIDs: make([]string, 0, len(r.Answers))}
+	for id, p := range r.Players {
+		_, answered := r.Answers[id]
+		if answered {
+			progress.PlayerIDs = append(progress.PlayerIDs, id)
+		}
+		if answered || p.Online {
+			progress.Total++
+		}
+	}
+	sort.Strings(progress.PlayerIDs)
+	progress.Answered = len(progress.PlayerIDs)
+	return progress
+}
+
 type RoundResult struct {
 	PlayerID         string `json:"playerId"`
 	Name             string `json:"name"`
+	Avatar           string `json:"avatar"`
+	Color            string `json:"color"`
 	SelectedOptionID string `json:"selectedOptionId,omitempty"`
 	Correct          bool   `json:"correct"`
 	Score            int    `json:"score"`
@@ -177,13 +430,61 @@ func (r *Room) FinishRoundIfDeadlinePassed() (*RoundResultsPayload, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if r.Phase != PhaseAnswering {
+	if r.Phase != PhaseAnswering || r.Paused {
 		return nil, false
 	}
 	if time.Now().Before(r.AnsweringDeadline) {
 		return nil, false
 	}
 
+	return r.finishRoundLocked(), true
+}
+
+func (r *Room) FinishRoundIfAllAnswered() (*RoundResultsPayload, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Settings.EndRoundEarly || r.Phase != PhaseAnswering || r.Paused {
+		return nil, false
+	}
+	online := 0
+	for id, p := range r.Players {
+		if !p.Online {
+			continue
+		}
+		online++
+		if _, ok := r.Answers[id]; !ok {
+			return nil, false
+		}
+	}
+	if online == 0 {
+		return nil, false
+	}
+
+	return r.finishRoundLocked(), true
+}
+
+func (r *Room) RevealAnswer(requesterID string) (*RoundResultsPayload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return nil, ErrNotHost
+	}
+	if !r.Settings.HostPaced {
+		return nil, ErrNotHostPaced
+	}
+	if r.Phase != PhaseAnswering {
+		return nil, ErrBadPhase
+	}
+	if r.Paused {
+		return nil, ErrPaused
+	}
+
+	return r.finishRoundLocked(), nil
+}
+
+func (r *Room) finishRoundLocked() *RoundResultsPayload {
 	if r.Scores == nil {
 		r.Scores = make(map[string]int)
 	}
@@ -204,6 +505,8 @@ func (r *Room) FinishRoundIfDeadlinePassed() (*RoundResultsPayload, bool) {
 		results = append(results, RoundResult{
 			PlayerID:         id,
 			Name:             p.Name,
+			Avatar:           p.Avatar,
+			Color:            p.Color,
 			SelectedOptionID: selected,
 			Correct:          isCorrect,
 			Score:            r.Scores[id],
@@ -212,7 +515,7 @@ func (r *Room) FinishRoundIfDeadlinePassed() (*RoundResultsPayload, bool) {
 
 	r.Phase = PhaseResults
 
-	payload := &RoundResultsPayload{
+	return &RoundResultsPayload{
 		Code:            r.Code,
 		RoundNumber:     r.RoundNumber,
 		Question:        r.CurrentQuestion.Text,
@@ -220,22 +523,162 @@ func (r *Room) FinishRoundIfDeadlinePassed() (*RoundResultsPayload, bool) {
 		CorrectOptionID: correctID,
 		Results:         results,
 	}
-	return payload, true
+}
+
+func (r *Room) UpdateSettings(requesterID string, settings RoomSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return ErrNotHost
+	}
+
+	r.Settings = settings
+	return nil
+}
+
+func (r *Room) Pause(requesterID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return ErrNotHost
+	}
+	if r.Phase != PhaseAnswering && r.Phase != PhaseResults {
+		return ErrBadPhase
+	}
+	if r.Paused {
+		return ErrPaused
+	}
+
+	r.Paused = true
+	r.PausedRemaining = 0
+	if r.Phase == PhaseAnswering {
+		remaining := time.Until(r.AnsweringDeadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+		r.PausedRemaining = remaining
+	}
+	return nil
+}
+
+func (r *Room) Resume(requesterID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return ErrNotHost
+	}
+	if !r.Paused {
+		return ErrNotPaused
+	}
+
+	r.Paused = false
+	if r.Phase == PhaseAnswering {
+		r.AnsweringDeadline = time.Now().Add(r.PausedRemaining)
+	}
+	r.PausedRemaining = 0
+	return nil
+}
+
+func (r *Room) ResetGame(requesterID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return ErrNotHost
+	}
+	if r.Phase != PhaseResults {
+		return ErrBadPhase
+	}
+
+	finished := BuildLeaderboard(r.snapshotLocked())
+	r.PreviousGame = &finished
+
+	r.Phase = PhaseLobby
+	r.CountdownDeadline = time.Time{}
+	r.Paused = false
+	r.PausedRemaining = 0
+	r.RoundNumber = 0
+	r.CurrentQuestion = Question{}
+	r.AnsweringDeadline = time.Time{}
+	r.Answers = make(map[string]string)
+	r.Scores = make(map[string]int, len(r.Players))
+	for id, p := range r.Players {
+		r.Scores[id] = 0
+		p.Ready = false
+	}
+	return nil
+}
+
+func (r *Room) Close(requesterID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.canControlLocked(requesterID) {
+		return ErrNotHost
+	}
+	return r.closeLocked()
+}
+
+func (r *Room) CloseWithToken(token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.hostTokenValidLocked(token) {
+		return ErrBadHostToken
+	}
+	return r.closeLocked()
+}
+
+func (r *Room) hostTokenValidLocked(token string) bool {
+	return r.HostToken != "" && subtle.ConstantTimeCompare([]byte(r.HostToken), []byte(token)) == 1
+}
+
+func (r *Room) closeLocked() error {
+	if r.Phase == PhaseClosed {
+		return ErrBadPhase
+	}
+
+	r.Phase = PhaseClosed
+	r.Paused = false
+	r.CountdownDeadline = time.Time{}
+	r.AnsweringDeadline = time.Time{}
+	return nil
+}
+
+func (r *Room) CurrentPhase() Phase {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.Phase
 }
 
 func (r *Room) Snapshot() RoomSnapshot {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.snapshotLocked()
+}
+
+func (r *Room) snapshotLocked() RoomSnapshot {
 	players := make([]*Player, 0, len(r.Players))
 	for _, p := range r.Players {
-		players = append(players, p)
+		cp := *p
+		players = append(players, &cp)
 	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].JoinOrder < players[j].JoinOrder
+	})
 
 	var deadlineMillis int64
-	if r.Phase == PhaseAnswering && !r.AnsweringDeadline.IsZero() {
+	if r.Phase == PhaseAnswering && !r.Paused && !r.AnsweringDeadline.IsZero() {
 		deadlineMillis = r.AnsweringDeadline.UnixMilli()
 	}
+	if r.Phase == PhaseCountdown && !r.CountdownDeadline.IsZero() {
+		deadlineMillis = r.CountdownDeadline.UnixMilli()
+	}
 
 	scoresCopy := make(map[string]int)
 	if r.Scores != nil {
@@ -250,8 +693,26 @@ func (r *Room) Snapshot() RoomSnapshot {
 		Phase:       r.Phase,
 		HostID:      r.HostID,
 		RoundNumber: r.RoundNumber,
+		Settings:    r.Settings,
 		Players:     players,
 		Scores:      scoresCopy,
+
+		Paused:     r.Paused,
+		Displays:   len(r.displays),
+		Spectators: len(r.spectators),
+
+		PreviousGame: r.PreviousGame,
+	}
+
+	if r.Paused && r.Phase == PhaseAnswering {
+		s.PausedRemainingMs = r.PausedRemaining.Milliseconds()
+	}
+
+	if r.Phase == PhaseAnswering {
+		s.Answered = make(map[string]bool, len(r.Answers))
+		for _, id := range r.answerProgressLocked().PlayerIDs {
+			s.Answered[id] = true
+		}
 	}
 
 	if r.Phase == PhaseAnswering || r.Phase == PhaseResults {
@@ -266,6 +727,16 @@ func (r *Room) Snapshot() RoomSnapshot {
 	return s
 }
 
+func RemainingMs(deadlineMillis int64, now time.Time) int64 {
+	if deadlineMillis == 0 {
+		return 0
+	}
+	if left := deadlineMillis - now.UnixMilli(); left > 0 {
+		return left
+	}
+	return 0
+}
+
 func hasOption(opts []Option, id string) bool {
 	for _, o := range opts {
 		if o.ID == id {
@@ -287,11 +758,13 @@ func NewRoomManager() *RoomManager {
 func (rm *RoomManager) CreateRoom() *Room {
 	code := rm.generateCode(4)
 	room := &Room{
-		Code:    code,
-		Phase:   PhaseLobby,
-		Players: make(map[string]*Player),
-		Answers: make(map[string]string),
-		Scores:  make(map[string]int),
+		Code:      code,
+		HostToken: generateToken(),
+		Phase:     PhaseLobby,
+		Settings:  DefaultRoomSettings(),
+		Players:   make(map[string]*Player),
+		Answers:   make(map[string]string),
+		Scores:    make(map[string]int),
 	}
 
 	rm.mu.Lock()
@@ -309,6 +782,24 @@ func (rm *RoomManager) GetRoom(code string) (*Room, bool) {
 	return r, ok
 }
 
+func (rm *RoomManager) DeleteRoom(code string) bool {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	code = strings.ToUpper(code)
+	if _, ok := rm.rooms[code]; !ok {
+		return false
+	}
+	delete(rm.rooms, code)
+	return true
+}
+
+func generateToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func (rm *RoomManager) generateCode(n int) string {
 	b := make([]byte, 8)
 	_, _ = rand.Read(b)
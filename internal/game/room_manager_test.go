@@ -44,6 +44,7 @@ func TestRoomManager_CreateRoom_Success(t *testing.T) {
 	require.NotNil(t, room)
 	require.Len(t, room.Code, 4)
 	require.Equal(t, PhaseLobby, room.Phase)
+	require.True(t, room.Settings.EndRoundEarly)
 	require.NotNil(t, room.Players)
 	require.NotNil(t, room.Answers)
 	require.NotNil(t, room.Scores)
@@ -234,3 +235,481 @@ func TestRoom_Snapshot_ScoresCopy(t *testing.T) {
 	snap2 := r.Snapshot()
 	require.NotEqual(t, 999, snap2.Scores[host.ID])
 }
+
+func TestRoom_RemovePlayer_HostSuccessionByJoinOrder(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	for _, id := range []string{"p2", "p3", "p4", "p5"} {
+		r.AddPlayer(&Player{ID: id, Name: id})
+	}
+
+	newHostID, changed := r.RemovePlayer(host.ID)
+	require.True(t, changed)
+	require.Equal(t, "p2", newHostID)
+	require.Equal(t, "p2", r.HostID)
+
+	newHostID, changed = r.RemovePlayer("p4")
+	require.False(t, changed)
+	require.Equal(t, "p2", newHostID)
+
+	newHostID, changed = r.RemovePlayer("p2")
+	require.True(t, changed)
+	require.Equal(t, "p3", newHostID)
+}
+
+func TestRoom_RemovePlayer_LastPlayer(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+
+	newHostID, changed := r.RemovePlayer(host.ID)
+	require.True(t, changed)
+	require.Empty(t, newHostID)
+	require.Empty(t, r.HostID)
+}
+
+func TestRoom_TransferHost_Success(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+
+	require.NoError(t, r.TransferHost(host.ID, "p2"))
+	require.Equal(t, "p2", r.HostID)
+
+	err := r.TransferHost(host.ID, "p2")
+	require.ErrorIs(t, err, ErrNotHost)
+}
+
+func TestRoom_TransferHost_UnknownPlayer(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+
+	err := r.TransferHost(host.ID, "ghost")
+	require.ErrorIs(t, err, ErrPlayerNotFound)
+	require.Equal(t, host.ID, r.HostID)
+}
+
+func TestRoom_TransferHost_OfflinePlayer(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+	require.True(t, r.DisconnectPlayer("p2", 0))
+
+	err := r.TransferHost(host.ID, "p2")
+	require.ErrorIs(t, err, ErrPlayerOffline)
+	require.Equal(t, host.ID, r.HostID)
+}
+
+func TestRoom_Snapshot_PlayersInJoinOrder(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "Zed"})
+	r.AddPlayer(&Player{ID: "p3", Name: "Amy"})
+
+	snap := r.Snapshot()
+	require.Len(t, snap.Players, 3)
+	require.Equal(t, "p1", snap.Players[0].ID)
+	require.Equal(t, "p2", snap.Players[1].ID)
+	require.Equal(t, "p3", snap.Players[2].ID)
+}
+
+func TestRoom_ResetGame_Success(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.NoError(t, r.SubmitAnswer(host.ID, "B"))
+	r.AnsweringDeadline = time.Now().Add(-1 * time.Second)
+	_, ok := r.FinishRoundIfDeadlinePassed()
+	require.True(t, ok)
+
+	require.NoError(t, r.ResetGame(host.ID))
+
+	snap := r.Snapshot()
+	require.Equal(t, PhaseLobby, snap.Phase)
+	require.Equal(t, 0, snap.RoundNumber)
+	require.Len(t, snap.Players, 2)
+	require.Equal(t, 0, snap.Scores[host.ID])
+	require.Empty(t, r.Answers)
+	require.Empty(t, snap.Question)
+
+	require.NotNil(t, snap.PreviousGame)
+	require.Equal(t, 1, snap.PreviousGame.RoundsPlayed)
+	require.Equal(t, host.ID, snap.PreviousGame.Leaderboard[0].PlayerID)
+	require.Equal(t, 1, snap.PreviousGame.Leaderboard[0].Score)
+}
+
+func TestRoom_ResetGame_NotHost(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+	r.Phase = PhaseResults
+
+	err := r.ResetGame("someone_else")
+	require.ErrorIs(t, err, ErrNotHost)
+}
+
+func TestRoom_ResetGame_BadPhase(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	err := r.ResetGame(host.ID)
+	require.ErrorIs(t, err, ErrBadPhase)
+}
+
+func TestRoom_Pause_FreezesAnswering(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	require.NoError(t, r.Pause(host.ID))
+
+	snap := r.Snapshot()
+	require.True(t, snap.Paused)
+	require.Zero(t, snap.Deadline)
+	require.InDelta(t, 30000, snap.PausedRemainingMs, 1000)
+
+	err := r.SubmitAnswer(host.ID, "A")
+	require.ErrorIs(t, err, ErrPaused)
+
+	r.AnsweringDeadline = time.Now().Add(-1 * time.Second)
+	payload, ok := r.FinishRoundIfDeadlinePassed()
+	require.False(t, ok)
+	require.Nil(t, payload)
+
+	err = r.Pause(host.ID)
+	require.ErrorIs(t, err, ErrPaused)
+}
+
+func TestRoom_Resume_RestoresDeadline(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.NoError(t, r.Pause(host.ID))
+	r.PausedRemaining = 10 * time.Second
+
+	require.NoError(t, r.Resume(host.ID))
+
+	snap := r.Snapshot()
+	require.False(t, snap.Paused)
+	require.Zero(t, snap.PausedRemainingMs)
+	require.WithinDuration(t, time.Now().Add(10*time.Second), time.UnixMilli(snap.Deadline), time.Second)
+	require.NoError(t, r.SubmitAnswer(host.ID, "A"))
+}
+
+func TestRoom_PauseResume_Errors(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+
+	err := r.Pause(host.ID)
+	require.ErrorIs(t, err, ErrBadPhase)
+
+	err = r.Resume(host.ID)
+	require.ErrorIs(t, err, ErrNotPaused)
+
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	err = r.Pause("someone_else")
+	require.ErrorIs(t, err, ErrNotHost)
+}
+
+func TestRoom_SetReady_CountsAndCancelsCountdown(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+
+	readyCount, total, err := r.SetReady(host.ID, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, readyCount)
+	require.Equal(t, 2, total)
+
+	readyCount, _, err = r.SetReady("p2", true)
+	require.NoError(t, err)
+	require.Equal(t, 2, readyCount)
+
+	require.NoError(t, r.StartCountdown(3*time.Second))
+	snap := r.Snapshot()
+	require.Equal(t, PhaseCountdown, snap.Phase)
+	require.NotZero(t, snap.Deadline)
+
+	readyCount, _, err = r.SetReady("p2", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, readyCount)
+	require.Equal(t, PhaseLobby, r.Phase)
+
+	_, _, err = r.SetReady("ghost", true)
+	require.ErrorIs(t, err, ErrPlayerNotFound)
+}
+
+func TestRoom_CheckReadyQuorum_FollowsRoster(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+
+	_, _, err := r.SetReady(host.ID, true)
+	require.NoError(t, err)
+
+	started, cancelled := r.CheckReadyQuorum(1, 3*time.Second)
+	require.False(t, started)
+	require.False(t, cancelled)
+
+	require.True(t, r.DisconnectPlayer("p2", 0))
+	started, cancelled = r.CheckReadyQuorum(1, 3*time.Second)
+	require.True(t, started)
+	require.False(t, cancelled)
+	require.Equal(t, PhaseCountdown, r.Phase)
+
+	r.AddPlayer(&Player{ID: "p3", Name: "P3"})
+	started, cancelled = r.CheckReadyQuorum(1, 3*time.Second)
+	require.False(t, started)
+	require.True(t, cancelled)
+	require.Equal(t, PhaseLobby, r.Phase)
+	require.True(t, r.CountdownDeadline.IsZero())
+}
+
+func TestRoom_SetReady_BadPhase(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	_, _, err := r.SetReady(host.ID, true)
+	require.ErrorIs(t, err, ErrBadPhase)
+}
+
+func TestRoom_StartGame_FromCountdownClearsReady(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	_, _, err := r.SetReady(host.ID, true)
+	require.NoError(t, err)
+	require.NoError(t, r.StartCountdown(3*time.Second))
+
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	snap := r.Snapshot()
+	require.Equal(t, PhaseAnswering, snap.Phase)
+	require.False(t, snap.Players[0].Ready)
+}
+
+func TestRoom_FinishRoundIfAllAnswered(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.Settings = DefaultRoomSettings()
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	require.NoError(t, r.SubmitAnswer(host.ID, "B"))
+	payload, ok := r.FinishRoundIfAllAnswered()
+	require.False(t, ok)
+	require.Nil(t, payload)
+
+	require.NoError(t, r.SubmitAnswer("p2", "A"))
+	payload, ok = r.FinishRoundIfAllAnswered()
+	require.True(t, ok)
+	require.Len(t, payload.Results, 2)
+	require.Equal(t, PhaseResults, r.Phase)
+	require.Equal(t, 1, r.Scores[host.ID])
+
+	_, ok = r.FinishRoundIfAllAnswered()
+	require.False(t, ok)
+}
+
+func TestRoom_FinishRoundIfAllAnswered_Disabled(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.UpdateSettings(host.ID, RoomSettings{EndRoundEarly: false}))
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.NoError(t, r.SubmitAnswer(host.ID, "B"))
+
+	_, ok := r.FinishRoundIfAllAnswered()
+	require.False(t, ok)
+	require.Equal(t, PhaseAnswering, r.Phase)
+}
+
+func TestRoom_UpdateSettings_NotHost(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+
+	err := r.UpdateSettings("someone_else", RoomSettings{})
+	require.ErrorIs(t, err, ErrNotHost)
+}
+
+func TestRoom_RevealAnswer_HostPaced(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.UpdateSettings(host.ID, RoomSettings{HostPaced: true}))
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.NoError(t, r.SubmitAnswer(host.ID, "B"))
+
+	_, err := r.RevealAnswer("someone_else")
+	require.ErrorIs(t, err, ErrNotHost)
+
+	payload, err := r.RevealAnswer(host.ID)
+	require.NoError(t, err)
+	require.Equal(t, "B", payload.CorrectOptionID)
+	require.Equal(t, PhaseResults, r.Phase)
+	require.Equal(t, 1, r.Scores[host.ID])
+
+	_, err = r.RevealAnswer(host.ID)
+	require.ErrorIs(t, err, ErrBadPhase)
+}
+
+func TestRoom_RevealAnswer_NotHostPaced(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	_, err := r.RevealAnswer(host.ID)
+	require.ErrorIs(t, err, ErrNotHostPaced)
+	require.Equal(t, PhaseAnswering, r.Phase)
+}
+
+func TestRoom_DisconnectAndResume_KeepsSeat(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	p2 := &Player{ID: "p2", Name: "P2", ResumeToken: "tok-2"}
+	r.AddPlayer(p2)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.NoError(t, r.SubmitAnswer(p2.ID, "B"))
+	r.Scores[p2.ID] = 3
+
+	require.True(t, r.DisconnectPlayer(p2.ID, 0))
+	require.False(t, r.DisconnectPlayer(p2.ID, 0))
+
+	snap := r.Snapshot()
+	require.Len(t, snap.Players, 2)
+	require.False(t, snap.Players[1].Online)
+
+	resumed, err := r.ResumePlayer("tok-2")
+	require.NoError(t, err)
+	require.Equal(t, p2.ID, resumed.ID)
+	require.Equal(t, 1, resumed.Session)
+	require.True(t, resumed.Online)
+	require.Equal(t, 3, r.Scores[p2.ID])
+	require.Equal(t, "B", r.PendingAnswer(p2.ID))
+
+	require.False(t, r.DisconnectPlayer(p2.ID, 0))
+	_, _, removed := r.RemoveIfOffline(p2.ID, 0)
+	require.False(t, removed)
+}
+
+func TestRoom_ResumePlayer_InvalidToken(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+
+	_, err := r.ResumePlayer("")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = r.ResumePlayer("nope")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRoom_RemoveIfOffline_PrefersOnlineSuccessor(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+	r.AddPlayer(&Player{ID: "p3", Name: "P3"})
+
+	require.True(t, r.DisconnectPlayer("p2", 0))
+	require.True(t, r.DisconnectPlayer(host.ID, 0))
+
+	newHostID, changed, removed := r.RemoveIfOffline(host.ID, 0)
+	require.True(t, removed)
+	require.True(t, changed)
+	require.Equal(t, "p3", newHostID)
+}
+
+func TestRoom_FinishRoundIfAllAnswered_IgnoresOffline(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.Settings = DefaultRoomSettings()
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.NoError(t, r.SubmitAnswer(host.ID, "A"))
+
+	require.True(t, r.DisconnectPlayer("p2", 0))
+
+	payload, ok := r.FinishRoundIfAllAnswered()
+	require.True(t, ok)
+	require.Len(t, payload.Results, 2)
+}
+
+func TestRoomManager_CreateRoom_HostTokenAndDelete(t *testing.T) {
+	rm := NewRoomManager()
+	room := rm.CreateRoom()
+	require.Len(t, room.HostToken, 32)
+
+	require.True(t, rm.DeleteRoom(stringsToLower(room.Code)))
+	require.False(t, rm.DeleteRoom(room.Code))
+
+	_, ok := rm.GetRoom(room.Code)
+	require.False(t, ok)
+}
+
+func TestRoom_Close(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	err := r.Close("someone_else")
+	require.ErrorIs(t, err, ErrNotHost)
+
+	require.NoError(t, r.Close(host.ID))
+	require.Equal(t, PhaseClosed, r.Phase)
+
+	err = r.SubmitAnswer(host.ID, "A")
+	require.ErrorIs(t, err, ErrBadPhase)
+
+	err = r.StartGame(host.ID, validQuestion(), 30)
+	require.ErrorIs(t, err, ErrBadPhase)
+
+	err = r.Close(host.ID)
+	require.ErrorIs(t, err, ErrBadPhase)
+}
+
+func TestRoom_CloseWithToken(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+	r.HostToken = "secret"
+
+	err := r.CloseWithToken("wrong")
+	require.ErrorIs(t, err, ErrBadHostToken)
+
+	err = r.CloseWithToken("")
+	require.ErrorIs(t, err, ErrBadHostToken)
+
+	require.NoError(t, r.CloseWithToken("secret"))
+	require.Equal(t, PhaseClosed, r.Phase)
+}
+
+func TestRoom_Snapshot_RemainingMsLeftToSender(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	snap := r.Snapshot()
+	require.NotZero(t, snap.Deadline)
+	require.Zero(t, snap.RemainingMs)
+}
+
+func TestRemainingMs(t *testing.T) {
+	now := time.UnixMilli(1_000_000)
+
+	require.Equal(t, int64(1500), RemainingMs(1_001_500, now))
+	require.Zero(t, RemainingMs(999_000, now))
+	require.Zero(t, RemainingMs(0, now))
+}
+
+func TestRoom_AnswerProgress(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.AddPlayer(&Player{ID: "p2", Name: "P2"})
+	r.AddPlayer(&Player{ID: "p3", Name: "P3"})
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+
+	progress := r.AnswerProgress()
+	require.Equal(t, 0, progress.Answered)
+	require.Equal(t, 3, progress.Total)
+	require.Empty(t, progress.PlayerIDs)
+
+	require.NoError(t, r.SubmitAnswer("p2", "A"))
+	require.True(t, r.DisconnectPlayer("p3", 0))
+
+	progress = r.AnswerProgress()
+	require.Equal(t, 1, progress.Answered)
+	require.Equal(t, 2, progress.Total)
+	require.Equal(t, []string{"p2"}, progress.PlayerIDs)
+
+	snap := r.Snapshot()
+	require.Equal(t, map[string]bool{"p2": true}, snap.Answered)
+}
+
+func TestRoom_Snapshot_AnsweredOnlyWhileAnswering(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	r.Settings = DefaultRoomSettings()
+	require.Nil(t, r.Snapshot().Answered)
+
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.NoError(t, r.SubmitAnswer(host.ID, "A"))
+	_, ok := r.FinishRoundIfAllAnswered()
+	require.True(t, ok)
+
+	require.Nil(t, r.Snapshot().Answered)
+}
+
+func TestRoom_CurrentPhase(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+	require.Equal(t, PhaseLobby, r.CurrentPhase())
+
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.Equal(t, PhaseAnswering, r.CurrentPhase())
+}
@@ -0,0 +1,39 @@
+package game
+
+const DefaultMaxSpectators = 50
+
+func (r *Room) AddSpectator(id string, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Phase == PhaseClosed {
+		return ErrBadPhase
+	}
+	if limit > 0 && len(r.spectators) >= limit {
+		return ErrSpectatorsFull
+	}
+	if r.spectators == nil {
+		r.spectators = make(map[string]struct{})
+	}
+	r.spectators[id] = struct{}{}
+	return nil
+}
+
+func (r *Room) RemoveSpectator(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.spectators[id]; !ok {
+		return false
+	}
+	delete(r.spectators, id)
+	return r.Phase != PhaseClosed
+}
+
+func (r *Room) IsSpectator(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.spectators[id]
+	return ok
+}
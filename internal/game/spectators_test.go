@@ -0,0 +1,43 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoom_Spectators_NotPlayers(t *testing.T) {
+	r, host := newTestRoomWithHost(t)
+
+	require.NoError(t, r.AddSpectator("judge", 0))
+	require.True(t, r.IsSpectator("judge"))
+
+	snap := r.Snapshot()
+	require.Equal(t, 1, snap.Spectators)
+	require.Len(t, snap.Players, 1)
+	require.NotContains(t, snap.Scores, "judge")
+
+	require.False(t, r.CanControl("judge"))
+	require.ErrorIs(t, r.TransferHost(host.ID, "judge"), ErrPlayerNotFound)
+
+	require.NoError(t, r.StartGame(host.ID, validQuestion(), 30))
+	require.ErrorIs(t, r.SubmitAnswer("judge", "B"), ErrPlayerNotFound)
+	require.Equal(t, 1, r.AnswerProgress().Total)
+
+	newHostID, _ := r.RemovePlayer(host.ID)
+	require.Empty(t, newHostID)
+
+	require.True(t, r.RemoveSpectator("judge"))
+	require.False(t, r.RemoveSpectator("judge"))
+	require.Equal(t, 0, r.Snapshot().Spectators)
+}
+
+func TestRoom_AddSpectator_Limit(t *testing.T) {
+	r, _ := newTestRoomWithHost(t)
+
+	require.NoError(t, r.AddSpectator("s1", 1))
+	require.ErrorIs(t, r.AddSpectator("s2", 1), ErrSpectatorsFull)
+
+	r.Phase = PhaseClosed
+	require.ErrorIs(t, r.AddSpectator("s3", 0), ErrBadPhase)
+}
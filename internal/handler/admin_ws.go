@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/ws"
+	"go.uber.org/zap"
+)
+
+func RegisterAdminWSHandlers(mux *http.ServeMux, hub *ws.Hub, adminToken string, log *zap.Logger) {
+	if log == nil {
+		log = zap.NewNop()
+	}
+
+	mux.HandleFunc("/admin/ws/stats", requireAdminToken(adminToken, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			log.Warn("method not allowed", zap.String("path", r.URL.Path), zap.String("method", r.Method))
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(hub.Stats())
+	}))
+}
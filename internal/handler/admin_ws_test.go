@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/ws"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAdminWSHandlers_Stats(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterAdminWSHandlers(mux, ws.NewHub(nil, zap.NewNop(), ws.Config{}), "secret", zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/ws/stats", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/ws/stats", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var stats ws.Stats
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stats))
+	require.Zero(t, stats.Connections)
+}
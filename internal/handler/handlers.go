@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
 	"github.com/ArtemMoroz51/FinalProject/internal/service"
 	"github.com/ArtemMoroz51/FinalProject/internal/ws"
 	"go.uber.org/zap"
@@ -23,16 +25,45 @@ func RegisterHandlers(mux *http.ServeMux, svc service.GameService, hub *ws.Hub,
 		}
 		room := svc.CreateRoom()
 		log.Info("room created", zap.String("code", room.Code))
-		_ = json.NewEncoder(w).Encode(map[string]string{"code": room.Code})
+		_ = json.NewEncoder(w).Encode(map[string]string{"code": room.Code, "hostToken": room.HostToken})
 	})
 
 	mux.HandleFunc("/rooms/", func(w http.ResponseWriter, r *http.Request) {
+		code, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/rooms/"), "/")
+		if action != "" {
+			serveRoomTransport(w, r, hub, code, action, log)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			room, gameOver, err := svc.CloseRoom(code, token)
+			switch {
+			case errors.Is(err, game.ErrRoomNotFound):
+				log.Warn("room not found", zap.String("code", code))
+				http.Error(w, "room not found", http.StatusNotFound)
+				return
+			case errors.Is(err, game.ErrBadHostToken):
+				log.Warn("room close unauthorized", zap.String("code", code))
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			case err != nil:
+				log.Warn("room close failed", zap.String("code", code), zap.Error(err))
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+
+			hub.CloseRoom(room.Code, gameOver)
+			log.Info("room closed", zap.String("code", room.Code))
+			_ = json.NewEncoder(w).Encode(gameOver)
+			return
+		}
+
 		if r.Method != http.MethodGet {
 			log.Warn("method not allowed", zap.String("path", r.URL.Path), zap.String("method", r.Method))
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		code := strings.TrimPrefix(r.URL.Path, "/rooms/")
 		room, ok := svc.GetRoom(code)
 		if !ok {
 			log.Warn("room not found", zap.String("code", code))
@@ -52,3 +83,29 @@ func RegisterHandlers(mux *http.ServeMux, svc service.GameService, hub *ws.Hub,
 		hub.ServeWS(w, r, code)
 	})
 }
+
+func serveRoomTransport(w http.ResponseWriter, r *http.Request, hub *ws.Hub, code, action string, log *zap.Logger) {
+	method := http.MethodPost
+	if action == "events" {
+		method = http.MethodGet
+	}
+	switch {
+	case action != "join" && action != "events" && action != "actions":
+		http.NotFound(w, r)
+		return
+	case r.Method != method:
+		log.Warn("method not allowed", zap.String("path", r.URL.Path), zap.String("method", r.Method))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch action {
+	case "join":
+		log.Info("http join attempt", zap.String("code", code))
+		hub.ServeJoin(w, r, code)
+	case "events":
+		hub.ServeEvents(w, r, code)
+	case "actions":
+		hub.ServeActions(w, r, code)
+	}
+}
@@ -33,11 +33,69 @@ func (m *mockGameService) GetRoom(code string) (*game.Room, bool) {
 	return r, ok
 }
 
+func (m *mockGameService) JoinRoom(room *game.Room, p *game.Player) (*game.Player, bool, error) {
+	args := m.Called(room, p)
+	joined, _ := args.Get(0).(*game.Player)
+	return joined, args.Bool(1), args.Error(2)
+}
+
+func (m *mockGameService) JoinSpectator(room *game.Room, id string) error {
+	args := m.Called(room, id)
+	return args.Error(0)
+}
+
+func (m *mockGameService) CheckReadyQuorum(room *game.Room) (bool, bool) {
+	args := m.Called(room)
+	return args.Bool(0), args.Bool(1)
+}
+
+func (m *mockGameService) MutePlayer(room *game.Room, requesterID, targetID string, muted bool) error {
+	args := m.Called(room, requesterID, targetID, muted)
+	return args.Error(0)
+}
+
+func (m *mockGameService) SetNotifier(n service.PlayerNotifier) {
+	m.Called(n)
+}
+
+func (m *mockGameService) SuggestName(room *game.Room, name string) string {
+	args := m.Called(room, name)
+	return args.String(0)
+}
+
 func (m *mockGameService) StartRound(ctx context.Context, room *game.Room, hostID string) error {
 	args := m.Called(ctx, room, hostID)
 	return args.Error(0)
 }
 
+func (m *mockGameService) ResetGame(room *game.Room, hostID string) error {
+	args := m.Called(room, hostID)
+	return args.Error(0)
+}
+
+func (m *mockGameService) SetReady(room *game.Room, playerID string, ready bool) (bool, error) {
+	args := m.Called(room, playerID, ready)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockGameService) SendChat(room *game.Room, playerID, text string) (game.ChatMessage, error) {
+	args := m.Called(room, playerID, text)
+	return args.Get(0).(game.ChatMessage), args.Error(1)
+}
+
+func (m *mockGameService) EndGame(room *game.Room, hostID string) (service.GameOverPayload, error) {
+	args := m.Called(room, hostID)
+	p, _ := args.Get(0).(service.GameOverPayload)
+	return p, args.Error(1)
+}
+
+func (m *mockGameService) CloseRoom(code, hostToken string) (*game.Room, service.GameOverPayload, error) {
+	args := m.Called(code, hostToken)
+	r, _ := args.Get(0).(*game.Room)
+	p, _ := args.Get(1).(service.GameOverPayload)
+	return r, p, args.Error(2)
+}
+
 func (m *mockGameService) MaxRounds() int {
 	args := m.Called()
 	return args.Int(0)
@@ -55,6 +113,12 @@ func (m *mockGameService) ResultsPause() time.Duration {
 	return d
 }
 
+func (m *mockGameService) StartCountdown() time.Duration {
+	args := m.Called()
+	d, _ := args.Get(0).(time.Duration)
+	return d
+}
+
 func (m *mockGameService) BuildLeaderboard(room *game.Room) service.GameOverPayload {
 	args := m.Called(room)
 	p, _ := args.Get(0).(service.GameOverPayload)
@@ -64,7 +128,7 @@ func (m *mockGameService) BuildLeaderboard(room *game.Room) service.GameOverPayl
 func TestHandlers_PostRooms_MethodNotAllowed(t *testing.T) {
 	mux := http.NewServeMux()
 	svc := new(mockGameService)
-	hub := ws.NewHub(nil, zap.NewNop())
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
 	RegisterHandlers(mux, svc, hub, zap.NewNop())
 
 	req := httptest.NewRequest(http.MethodGet, "/rooms", nil)
@@ -79,10 +143,10 @@ func TestHandlers_PostRooms_Success(t *testing.T) {
 	mux := http.NewServeMux()
 	svc := new(mockGameService)
 
-	room := &game.Room{Code: "ABCD", Phase: game.PhaseLobby}
+	room := &game.Room{Code: "ABCD", Phase: game.PhaseLobby, HostToken: "tok"}
 	svc.On("CreateRoom").Return(room).Once()
 
-	hub := ws.NewHub(nil, zap.NewNop())
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
 	RegisterHandlers(mux, svc, hub, zap.NewNop())
 
 	req := httptest.NewRequest(http.MethodPost, "/rooms", nil)
@@ -95,6 +159,7 @@ func TestHandlers_PostRooms_Success(t *testing.T) {
 	var resp map[string]string
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 	require.Equal(t, "ABCD", resp["code"])
+	require.Equal(t, "tok", resp["hostToken"])
 
 	svc.AssertExpectations(t)
 }
@@ -102,7 +167,7 @@ func TestHandlers_PostRooms_Success(t *testing.T) {
 func TestHandlers_GetRoom_MethodNotAllowed(t *testing.T) {
 	mux := http.NewServeMux()
 	svc := new(mockGameService)
-	hub := ws.NewHub(nil, zap.NewNop())
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
 	RegisterHandlers(mux, svc, hub, zap.NewNop())
 
 	req := httptest.NewRequest(http.MethodPost, "/rooms/ABCD", nil)
@@ -119,7 +184,7 @@ func TestHandlers_GetRoom_NotFound(t *testing.T) {
 
 	svc.On("GetRoom", "ABCD").Return((*game.Room)(nil), false).Once()
 
-	hub := ws.NewHub(nil, zap.NewNop())
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
 	RegisterHandlers(mux, svc, hub, zap.NewNop())
 
 	req := httptest.NewRequest(http.MethodGet, "/rooms/ABCD", nil)
@@ -138,7 +203,7 @@ func TestHandlers_GetRoom_Success(t *testing.T) {
 	room := &game.Room{Code: "ABCD", Phase: game.PhaseLobby}
 	svc.On("GetRoom", "ABCD").Return(room, true).Once()
 
-	hub := ws.NewHub(nil, zap.NewNop())
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
 	RegisterHandlers(mux, svc, hub, zap.NewNop())
 
 	req := httptest.NewRequest(http.MethodGet, "/rooms/ABCD", nil)
@@ -155,3 +220,86 @@ func TestHandlers_GetRoom_Success(t *testing.T) {
 
 	svc.AssertExpectations(t)
 }
+
+func TestHandlers_DeleteRoom_NotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	svc := new(mockGameService)
+
+	svc.On("CloseRoom", "ABCD", "tok").Return((*game.Room)(nil), service.GameOverPayload{}, game.ErrRoomNotFound).Once()
+
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
+	RegisterHandlers(mux, svc, hub, zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodDelete, "/rooms/ABCD", nil)
+	req.Header.Set("Authorization", "Bearer tok")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlers_DeleteRoom_Unauthorized(t *testing.T) {
+	mux := http.NewServeMux()
+	svc := new(mockGameService)
+
+	svc.On("CloseRoom", "ABCD", "").Return((*game.Room)(nil), service.GameOverPayload{}, game.ErrBadHostToken).Once()
+
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
+	RegisterHandlers(mux, svc, hub, zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodDelete, "/rooms/ABCD", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlers_DeleteRoom_Success(t *testing.T) {
+	mux := http.NewServeMux()
+	svc := new(mockGameService)
+
+	room := &game.Room{Code: "ABCD", Phase: game.PhaseClosed}
+	gameOver := service.GameOverPayload{Code: "ABCD", RoundsPlayed: 2}
+	svc.On("CloseRoom", "ABCD", "tok").Return(room, gameOver, nil).Once()
+
+	hub := ws.NewHub(nil, zap.NewNop(), ws.Config{})
+	RegisterHandlers(mux, svc, hub, zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodDelete, "/rooms/ABCD", nil)
+	req.Header.Set("Authorization", "Bearer tok")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp service.GameOverPayload
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "ABCD", resp.Code)
+	require.Equal(t, 2, resp.RoundsPlayed)
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandlers_RoomTransport_Routing(t *testing.T) {
+	mux := http.NewServeMux()
+	svc := new(mockGameService)
+	hub := ws.NewHub(svc, zap.NewNop(), ws.Config{})
+	RegisterHandlers(mux, svc, hub, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rooms/ABCD/join", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rooms/ABCD/teleport", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rooms/ABCD/events?session=nope", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
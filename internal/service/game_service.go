@@ -7,34 +7,54 @@ import (
 	"github.com/ArtemMoroz51/FinalProject/internal/game"
 )
 
-type LeaderboardEntry struct {
-	Place    int    `json:"place"`
-	PlayerID string `json:"playerId"`
-	Name     string `json:"name"`
-	Score    int    `json:"score"`
-}
-
-type GameOverPayload struct {
-	Code         string             `json:"code"`
-	RoundsPlayed int                `json:"roundsPlayed"`
-	Leaderboard  []LeaderboardEntry `json:"leaderboard"`
-}
+type (
+	LeaderboardEntry = game.LeaderboardEntry
+	GameOverPayload  = game.GameOverPayload
+)
 
 type Config struct {
 	AnsweringSeconds time.Duration
 	ResultsPause     time.Duration
 	MaxRounds        int
+
+	ReadyQuorum    float64
+	StartCountdown time.Duration
+
+	NameRules game.NameRules
+	ChatRules game.ChatRules
+
+	MaxSpectators int
 }
 
 type GameService interface {
 	CreateRoom() *game.Room
 	GetRoom(code string) (*game.Room, bool)
+	JoinRoom(room *game.Room, p *game.Player) (joined *game.Player, isHost bool, err error)
+	JoinSpectator(room *game.Room, id string) error
+	SuggestName(room *game.Room, name string) string
 
 	StartRound(ctx context.Context, room *game.Room, hostID string) error
+	ResetGame(room *game.Room, hostID string) error
+	EndGame(room *game.Room, hostID string) (GameOverPayload, error)
+	CloseRoom(code, hostToken string) (*game.Room, GameOverPayload, error)
+	SetReady(room *game.Room, playerID string, ready bool) (countdownStarted bool, err error)
+	CheckReadyQuorum(room *game.Room) (started, cancelled bool)
+	SendChat(room *game.Room, playerID, text string) (game.ChatMessage, error)
+	MutePlayer(room *game.Room, requesterID, targetID string, muted bool) error
 
 	MaxRounds() int
 	AnsweringSeconds() time.Duration
 	ResultsPause() time.Duration
+	StartCountdown() time.Duration
 
 	BuildLeaderboard(room *game.Room) GameOverPayload
+
+	SetNotifier(n PlayerNotifier)
+}
+
+type Personalize func(playerID string) interface{}
+
+type PlayerNotifier interface {
+	SendTo(roomCode, playerID, msgType string, payload interface{}) bool
+	BroadcastEach(roomCode, msgType string, payload interface{}, personalize Personalize)
 }
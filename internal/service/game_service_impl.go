@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sort"
 	"time"
 
 	"github.com/ArtemMoroz51/FinalProject/internal/game"
@@ -15,6 +14,8 @@ type gameService struct {
 	rm  *game.RoomManager
 	qs  storage.QuestionStore
 	cfg Config
+
+	notifier PlayerNotifier
 }
 
 func NewGameService(rm *game.RoomManager, qs storage.QuestionStore, cfg Config) GameService {
@@ -27,6 +28,38 @@ func NewGameService(rm *game.RoomManager, qs storage.QuestionStore, cfg Config)
 	if cfg.MaxRounds == 0 {
 		cfg.MaxRounds = 5
 	}
+	if cfg.ReadyQuorum <= 0 || cfg.ReadyQuorum > 1 {
+		cfg.ReadyQuorum = 1
+	}
+	if cfg.StartCountdown == 0 {
+		cfg.StartCountdown = 3 * time.Second
+	}
+	defaultNames := game.DefaultNameRules()
+	if cfg.NameRules.MinLen <= 0 {
+		cfg.NameRules.MinLen = defaultNames.MinLen
+	}
+	if cfg.NameRules.MaxLen <= 0 {
+		cfg.NameRules.MaxLen = defaultNames.MaxLen
+	}
+	if cfg.NameRules.Conflict == "" {
+		cfg.NameRules.Conflict = defaultNames.Conflict
+	}
+	defaultChat := game.DefaultChatRules()
+	if cfg.ChatRules.MaxLen <= 0 {
+		cfg.ChatRules.MaxLen = defaultChat.MaxLen
+	}
+	if cfg.ChatRules.HistorySize <= 0 {
+		cfg.ChatRules.HistorySize = defaultChat.HistorySize
+	}
+	if cfg.ChatRules.RateLimit <= 0 {
+		cfg.ChatRules.RateLimit = defaultChat.RateLimit
+	}
+	if cfg.ChatRules.RateWindow <= 0 {
+		cfg.ChatRules.RateWindow = defaultChat.RateWindow
+	}
+	if cfg.MaxSpectators <= 0 {
+		cfg.MaxSpectators = game.DefaultMaxSpectators
+	}
 	return &gameService{rm: rm, qs: qs, cfg: cfg}
 }
 
@@ -38,6 +71,23 @@ func (s *gameService) GetRoom(code string) (*game.Room, bool) {
 	return s.rm.GetRoom(code)
 }
 
+func (s *gameService) JoinRoom(room *game.Room, p *game.Player) (*game.Player, bool, error) {
+	name, err := s.cfg.NameRules.Normalize(p.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	p.Name = name
+	return room.JoinPlayer(p, s.cfg.NameRules)
+}
+
+func (s *gameService) SuggestName(room *game.Room, name string) string {
+	return room.SuggestName(name, s.cfg.NameRules)
+}
+
+func (s *gameService) JoinSpectator(room *game.Room, id string) error {
+	return room.AddSpectator(id, s.cfg.MaxSpectators)
+}
+
 func (s *gameService) StartRound(ctx context.Context, room *game.Room, hostID string) error {
 	q, err := s.qs.GetRandomActive(ctx)
 	if err != nil {
@@ -49,53 +99,75 @@ func (s *gameService) StartRound(ctx context.Context, room *game.Room, hostID st
 	return room.StartGame(hostID, q, int(s.cfg.AnsweringSeconds.Seconds()))
 }
 
+func (s *gameService) SetReady(room *game.Room, playerID string, ready bool) (bool, error) {
+	readyCount, total, err := room.SetReady(playerID, ready)
+	if err != nil {
+		return false, err
+	}
+	if !ready || total == 0 || float64(readyCount) < s.cfg.ReadyQuorum*float64(total) {
+		return false, nil
+	}
+	return room.StartCountdown(s.cfg.StartCountdown) == nil, nil
+}
+
+func (s *gameService) CheckReadyQuorum(room *game.Room) (started, cancelled bool) {
+	return room.CheckReadyQuorum(s.cfg.ReadyQuorum, s.cfg.StartCountdown)
+}
+
+func (s *gameService) SendChat(room *game.Room, playerID, text string) (game.ChatMessage, error) {
+	text, err := s.cfg.ChatRules.Normalize(text)
+	if err != nil {
+		return game.ChatMessage{}, err
+	}
+	return room.PostChat(playerID, text, s.cfg.ChatRules, time.Now())
+}
+
+func (s *gameService) MutePlayer(room *game.Room, requesterID, targetID string, muted bool) error {
+	if err := room.MutePlayer(requesterID, targetID, muted); err != nil {
+		return err
+	}
+	if s.notifier != nil {
+		s.notifier.SendTo(room.Code, targetID, "chat_muted", game.ChatMutedPayload{Muted: muted})
+	}
+	return nil
+}
+
+func (s *gameService) SetNotifier(n PlayerNotifier) {
+	s.notifier = n
+}
+
 func (s *gameService) MaxRounds() int                  { return s.cfg.MaxRounds }
 func (s *gameService) AnsweringSeconds() time.Duration { return s.cfg.AnsweringSeconds }
 func (s *gameService) ResultsPause() time.Duration     { return s.cfg.ResultsPause }
+func (s *gameService) StartCountdown() time.Duration   { return s.cfg.StartCountdown }
 
-func (s *gameService) BuildLeaderboard(room *game.Room) GameOverPayload {
-	snap := room.Snapshot()
-
-	type row struct {
-		id    string
-		name  string
-		score int
-	}
-	rows := make([]row, 0, len(snap.Players))
-	for _, p := range snap.Players {
-		rows = append(rows, row{
-			id:    p.ID,
-			name:  p.Name,
-			score: snap.Scores[p.ID],
-		})
+func (s *gameService) ResetGame(room *game.Room, hostID string) error {
+	if room.Snapshot().RoundNumber < s.cfg.MaxRounds {
+		return game.ErrGameNotFinished
 	}
+	return room.ResetGame(hostID)
+}
 
-	sort.Slice(rows, func(i, j int) bool {
-		if rows[i].score != rows[j].score {
-			return rows[i].score > rows[j].score
-		}
-		return rows[i].name < rows[j].name
-	})
-
-	leaderboard := make([]LeaderboardEntry, 0, len(rows))
-	place := 0
-	prevScore := -1
-	for i, r := range rows {
-		if i == 0 || r.score != prevScore {
-			place = i + 1
-			prevScore = r.score
-		}
-		leaderboard = append(leaderboard, LeaderboardEntry{
-			Place:    place,
-			PlayerID: r.id,
-			Name:     r.name,
-			Score:    r.score,
-		})
+func (s *gameService) EndGame(room *game.Room, hostID string) (GameOverPayload, error) {
+	if err := room.Close(hostID); err != nil {
+		return GameOverPayload{}, err
 	}
+	s.rm.DeleteRoom(room.Code)
+	return s.BuildLeaderboard(room), nil
+}
 
-	return GameOverPayload{
-		Code:         snap.Code,
-		RoundsPlayed: snap.RoundNumber,
-		Leaderboard:  leaderboard,
+func (s *gameService) CloseRoom(code, hostToken string) (*game.Room, GameOverPayload, error) {
+	room, ok := s.rm.GetRoom(code)
+	if !ok {
+		return nil, GameOverPayload{}, game.ErrRoomNotFound
+	}
+	if err := room.CloseWithToken(hostToken); err != nil {
+		return nil, GameOverPayload{}, err
 	}
+	s.rm.DeleteRoom(room.Code)
+	return room, s.BuildLeaderboard(room), nil
+}
+
+func (s *gameService) BuildLeaderboard(room *game.Room) GameOverPayload {
+	return game.BuildLeaderboard(room.Snapshot())
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -140,3 +141,236 @@ func TestGameService_BuildLeaderboard_SortsAndPlaces(t *testing.T) {
 	require.Equal(t, 1, payload.Leaderboard[1].Place)
 	require.Equal(t, 2, payload.Leaderboard[1].Score)
 }
+
+func TestGameService_ResetGame_NotFinished(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{MaxRounds: 3})
+
+	room, host, _ := makeRoomWithPlayers(t)
+	room.Phase = game.PhaseResults
+	room.RoundNumber = 2
+
+	err := svc.ResetGame(room, host.ID)
+	require.ErrorIs(t, err, game.ErrGameNotFinished)
+	require.Equal(t, 2, room.RoundNumber)
+}
+
+func TestGameService_ResetGame_Success(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{MaxRounds: 3})
+
+	room, host, p2 := makeRoomWithPlayers(t)
+	room.Phase = game.PhaseResults
+	room.RoundNumber = 3
+	room.Scores[p2.ID] = 2
+
+	require.NoError(t, svc.ResetGame(room, host.ID))
+
+	snap := room.Snapshot()
+	require.Equal(t, game.PhaseLobby, snap.Phase)
+	require.Equal(t, 0, snap.RoundNumber)
+	require.Equal(t, 0, snap.Scores[p2.ID])
+	require.NotNil(t, snap.PreviousGame)
+	require.Equal(t, p2.ID, snap.PreviousGame.Leaderboard[0].PlayerID)
+}
+
+func TestGameService_SetReady_StartsCountdownOnQuorum(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{ReadyQuorum: 0.5, StartCountdown: 2 * time.Second})
+
+	room, host, p2 := makeRoomWithPlayers(t)
+	room.AddPlayer(&game.Player{ID: "p3", Name: "Carol"})
+
+	started, err := svc.SetReady(room, host.ID, true)
+	require.NoError(t, err)
+	require.False(t, started)
+	require.Equal(t, game.PhaseLobby, room.Phase)
+
+	started, err = svc.SetReady(room, p2.ID, true)
+	require.NoError(t, err)
+	require.True(t, started)
+	require.Equal(t, game.PhaseCountdown, room.Phase)
+}
+
+func TestGameService_SetReady_DefaultQuorumIsEveryone(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{})
+	require.Equal(t, 3*time.Second, svc.StartCountdown())
+
+	room, host, p2 := makeRoomWithPlayers(t)
+
+	started, err := svc.SetReady(room, host.ID, true)
+	require.NoError(t, err)
+	require.False(t, started)
+
+	started, err = svc.SetReady(room, p2.ID, true)
+	require.NoError(t, err)
+	require.True(t, started)
+}
+
+func TestGameService_CheckReadyQuorum_AfterDisconnect(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{ReadyQuorum: 0.5})
+
+	room, host, p2 := makeRoomWithPlayers(t)
+	room.AddPlayer(&game.Player{ID: "p3", Name: "Carol"})
+
+	started, err := svc.SetReady(room, host.ID, true)
+	require.NoError(t, err)
+	require.False(t, started)
+
+	require.True(t, room.DisconnectPlayer(p2.ID, p2.Session))
+	started, cancelled := svc.CheckReadyQuorum(room)
+	require.True(t, started)
+	require.False(t, cancelled)
+	require.Equal(t, game.PhaseCountdown, room.Phase)
+}
+
+func TestGameService_JoinRoom_NormalizesAndRejectsDuplicates(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{})
+
+	room, _, _ := makeRoomWithPlayers(t)
+
+	p, isHost, err := svc.JoinRoom(room, &game.Player{ID: "p3", Name: "  Carol  "})
+	require.NoError(t, err)
+	require.False(t, isHost)
+	require.Equal(t, "Carol", p.Name)
+
+	_, _, err = svc.JoinRoom(room, &game.Player{ID: "p4", Name: "alice"})
+	require.ErrorIs(t, err, game.ErrNameTaken)
+
+	_, _, err = svc.JoinRoom(room, &game.Player{ID: "p5", Name: strings.Repeat("x", 25)})
+	require.ErrorIs(t, err, game.ErrNameTooLong)
+}
+
+func TestGameService_JoinRoom_AutoSuffix(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{NameRules: game.NameRules{Conflict: game.NameConflictAutoSuffix}})
+
+	room, _, _ := makeRoomWithPlayers(t)
+
+	p, _, err := svc.JoinRoom(room, &game.Player{ID: "p3", Name: "Alice"})
+	require.NoError(t, err)
+	require.Equal(t, "Alice 2", p.Name)
+}
+
+func TestGameService_EndGame_RemovesRoom(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{})
+
+	room := svc.CreateRoom()
+	host := &game.Player{ID: "p1", Name: "Host"}
+	room.AddPlayer(host)
+	room.Scores[host.ID] = 4
+
+	_, err := svc.EndGame(room, "someone_else")
+	require.ErrorIs(t, err, game.ErrNotHost)
+
+	gameOver, err := svc.EndGame(room, host.ID)
+	require.NoError(t, err)
+	require.Equal(t, room.Code, gameOver.Code)
+	require.Equal(t, 4, gameOver.Leaderboard[0].Score)
+
+	_, ok := svc.GetRoom(room.Code)
+	require.False(t, ok)
+}
+
+func TestGameService_CloseRoom_ByHostToken(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{})
+
+	room := svc.CreateRoom()
+
+	_, _, err := svc.CloseRoom("ZZZZ", room.HostToken)
+	require.ErrorIs(t, err, game.ErrRoomNotFound)
+
+	_, _, err = svc.CloseRoom(room.Code, "wrong")
+	require.ErrorIs(t, err, game.ErrBadHostToken)
+
+	closed, gameOver, err := svc.CloseRoom(room.Code, room.HostToken)
+	require.NoError(t, err)
+	require.Equal(t, room, closed)
+	require.Equal(t, room.Code, gameOver.Code)
+	require.Equal(t, game.PhaseClosed, closed.Phase)
+
+	_, ok := svc.GetRoom(room.Code)
+	require.False(t, ok)
+}
+
+func TestGameService_SendChat_AppliesRules(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{ChatRules: game.ChatRules{MaxLen: 20, BannedWords: []string{"spoiler"}}})
+
+	room := svc.CreateRoom()
+	host := &game.Player{ID: "p1", Name: "Host"}
+	room.AddPlayer(host)
+
+	msg, err := svc.SendChat(room, host.ID, "  no spoiler  please ")
+	require.NoError(t, err)
+	require.Equal(t, "no ******* please", msg.Text)
+	require.Equal(t, "Host", msg.Name)
+
+	_, err = svc.SendChat(room, host.ID, strings.Repeat("x", 21))
+	require.ErrorIs(t, err, game.ErrChatTooLong)
+
+	_, err = svc.SendChat(room, "ghost", "hi")
+	require.ErrorIs(t, err, game.ErrPlayerNotFound)
+
+	require.Len(t, room.ChatHistory(), 1)
+}
+
+func TestGameService_JoinSpectator_LimitAndLeaderboard(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{MaxSpectators: 2})
+
+	room, _, _ := makeRoomWithPlayers(t)
+
+	require.NoError(t, svc.JoinSpectator(room, "s1"))
+	require.NoError(t, svc.JoinSpectator(room, "s2"))
+	require.ErrorIs(t, svc.JoinSpectator(room, "s3"), game.ErrSpectatorsFull)
+
+	require.Equal(t, 2, room.Snapshot().Spectators)
+	require.Len(t, svc.BuildLeaderboard(room).Leaderboard, 2)
+}
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) SendTo(roomCode, playerID, msgType string, payload interface{}) bool {
+	args := m.Called(roomCode, playerID, msgType, payload)
+	return args.Bool(0)
+}
+
+func (m *mockNotifier) BroadcastEach(roomCode, msgType string, payload interface{}, personalize Personalize) {
+	m.Called(roomCode, msgType, payload, personalize)
+}
+
+func TestGameService_MutePlayer_NotifiesTarget(t *testing.T) {
+	rm := game.NewRoomManager()
+	qs := new(mockQuestionStore)
+	svc := NewGameService(rm, qs, Config{})
+	n := new(mockNotifier)
+	svc.SetNotifier(n)
+
+	room, host, p2 := makeRoomWithPlayers(t)
+	n.On("SendTo", room.Code, p2.ID, "chat_muted", game.ChatMutedPayload{Muted: true}).Return(true).Once()
+
+	require.NoError(t, svc.MutePlayer(room, host.ID, p2.ID, true))
+	require.True(t, room.Players[p2.ID].Muted)
+
+	require.ErrorIs(t, svc.MutePlayer(room, p2.ID, host.ID, true), game.ErrNotHost)
+	n.AssertExpectations(t)
+}
@@ -0,0 +1,136 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+)
+
+type Direction string
+
+const (
+	ClientToServer Direction = "client"
+	ServerToClient Direction = "server"
+)
+
+type MessageSpec struct {
+	Type      string
+	Direction Direction
+	Payload   interface{}
+	Summary   string
+}
+
+var Catalog = []MessageSpec{
+	{Type: "join_room", Direction: ClientToServer, Payload: JoinPayload{}, Summary: "First message: join the room as a new player."},
+	{Type: "resume", Direction: ClientToServer, Payload: ResumePayload{}, Summary: "First message: reclaim a seat with a resume token."},
+	{Type: "start_game", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Host starts the next round."},
+	{Type: "next_round", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Host advances a host-paced room from results."},
+	{Type: "reveal_answer", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Host ends answering early in a host-paced room."},
+	{Type: "submit_answer", Direction: ClientToServer, Payload: SubmitAnswerPayload{}, Summary: "Player locks in an answer."},
+	{Type: "set_ready", Direction: ClientToServer, Payload: SetReadyPayload{}, Summary: "Player toggles the lobby ready flag."},
+	{Type: "update_settings", Direction: ClientToServer, Payload: UpdateSettingsPayload{}, Summary: "Host changes room settings."},
+	{Type: "pause_game", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Host pauses the game."},
+	{Type: "resume_game", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Host resumes a paused game."},
+	{Type: "play_again", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Host resets a finished game."},
+	{Type: "reset_game", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Alias of play_again."},
+	{Type: "end_game", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Host ends the game and closes the room."},
+	{Type: "transfer_host", Direction: ClientToServer, Payload: TransferHostPayload{}, Summary: "Host hands control to another player."},
+	{Type: "sync", Direction: ClientToServer, Payload: SyncPayload{}, Summary: "Request replay of events after lastSeq."},
+	{Type: "chat_send", Direction: ClientToServer, Payload: ChatSendPayload{}, Summary: "Post a chat message to the room."},
+	{Type: "mute_player", Direction: ClientToServer, Payload: MutePlayerPayload{}, Summary: "Host mutes or unmutes a player in chat."},
+	{Type: "react", Direction: ClientToServer, Payload: ReactPayload{}, Summary: "Send an emoji reaction during results or game over."},
+	{Type: "time_sync", Direction: ClientToServer, Payload: TimeSyncPayload{}, Summary: "Clock sync probe; clientTime is the client clock in epoch ms."},
+	{Type: "get_state", Direction: ClientToServer, Payload: EmptyPayload{}, Summary: "Request a full room_state, e.g. after a patch with an unknown baseVersion."},
+
+	{Type: "welcome", Direction: ServerToClient, Payload: WelcomePayload{}, Summary: "Negotiated protocol version and server features."},
+	{Type: "joined", Direction: ServerToClient, Payload: JoinedPayload{}, Summary: "Private confirmation with player id and resume token."},
+	{Type: "player_joined", Direction: ServerToClient, Payload: game.Player{}, Summary: "A new player joined."},
+	{Type: "player_resumed", Direction: ServerToClient, Payload: game.Player{}, Summary: "A player reclaimed their seat."},
+	{Type: "room_state", Direction: ServerToClient, Payload: VersionedRoomState{}, Summary: "Full room snapshot; stateVersion is set for protocol v3."},
+	{Type: "controller_state", Direction: ServerToClient, Payload: ControllerState{}, Summary: "Trimmed room state sent instead of room_state to clients that joined with role controller."},
+	{Type: "room_patch", Direction: ServerToClient, Payload: RoomPatchPayload{}, Summary: "JSON Patch (RFC 6902) from baseVersion to stateVersion of room_state (protocol v3)."},
+	{Type: "chat_message", Direction: ServerToClient, Payload: game.ChatMessage{}, Summary: "A chat message posted to the room."},
+	{Type: "chat_muted", Direction: ServerToClient, Payload: game.ChatMutedPayload{}, Summary: "Private notice that the host muted or unmuted this player."},
+	{Type: "chat_history", Direction: ServerToClient, Payload: ChatHistoryPayload{}, Summary: "Recent chat messages, sent privately after joining."},
+	{Type: "reactions", Direction: ServerToClient, Payload: ReactionsPayload{}, Summary: "Reactions aggregated over the last interval; not sequenced or replayed."},
+	{Type: "time_sync", Direction: ServerToClient, Payload: TimeSyncReplyPayload{}, Summary: "Clock sync reply with server receive and send timestamps in epoch ms."},
+	{Type: "answer_accepted", Direction: ServerToClient, Payload: AnswerAcceptedPayload{}, Summary: "Private acknowledgement of submit_answer."},
+	{Type: "answer_progress", Direction: ServerToClient, Payload: game.AnswerProgress{}, Summary: "Who has locked in an answer; never includes the chosen option."},
+	{Type: "host_changed", Direction: ServerToClient, Payload: HostChangedPayload{}, Summary: "Host role moved to another player."},
+	{Type: "round_results", Direction: ServerToClient, Payload: PersonalRoundResults{}, Summary: "Correct answer and per-player results; v2+ players also get yourAnswer and yourRank."},
+	{Type: "game_over", Direction: ServerToClient, Payload: game.GameOverPayload{}, Summary: "Final leaderboard."},
+	{Type: "game_reset", Direction: ServerToClient, Payload: game.GameOverPayload{}, Summary: "Leaderboard of the game that was just reset."},
+	{Type: "room_closed", Direction: ServerToClient, Payload: RoomClosedPayload{}, Summary: "The room was closed; the connection will be closed."},
+	{Type: "error", Direction: ServerToClient, Payload: ErrorPayload{}, Summary: "Request failed."},
+}
+
+var clientMessages = func() map[string]MessageSpec {
+	m := make(map[string]MessageSpec)
+	for _, spec := range Catalog {
+		if spec.Direction == ClientToServer {
+			m[spec.Type] = spec
+		}
+	}
+	return m
+}()
+
+var ErrUnknownMessageType = errors.New("unknown message type")
+
+type PayloadError struct {
+	Type  string
+	Code  string
+	Field string
+	Err   error
+}
+
+func (e *PayloadError) Error() string { return e.Err.Error() }
+func (e *PayloadError) Unwrap() error { return e.Err }
+
+func (e *PayloadError) Envelope() Envelope {
+	return Envelope{Type: "error", Payload: ErrorPayload{
+		Message: e.Err.Error(),
+		Code:    e.Code,
+		Type:    e.Type,
+		Field:   e.Field,
+	}}
+}
+
+func decodeClientPayload(msgType string, raw json.RawMessage) (interface{}, error) {
+	spec, ok := clientMessages[msgType]
+	if !ok {
+		return nil, &PayloadError{Type: msgType, Code: "unknown_type", Err: ErrUnknownMessageType}
+	}
+
+	dst := reflect.New(reflect.TypeOf(spec.Payload)).Interface()
+
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return dst, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		perr := &PayloadError{Type: msgType, Code: "invalid_payload", Err: err}
+
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr):
+			perr.Code = "wrong_type"
+			perr.Field = typeErr.Field
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			perr.Code = "unknown_field"
+			perr.Field = strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		}
+		return nil, perr
+	}
+	if dec.More() {
+		return nil, &PayloadError{Type: msgType, Code: "invalid_payload", Err: fmt.Errorf("unexpected data after payload")}
+	}
+	return dst, nil
+}
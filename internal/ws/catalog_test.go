@@ -0,0 +1,89 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeClientPayload_Valid(t *testing.T) {
+	payload, err := decodeClientPayload("submit_answer", json.RawMessage(`{"optionId":"B"}`))
+	require.NoError(t, err)
+	require.Equal(t, "B", payload.(*SubmitAnswerPayload).OptionID)
+
+	payload, err = decodeClientPayload("join_room", json.RawMessage(`{"name":"Alex","protocolVersion":2}`))
+	require.NoError(t, err)
+	jp := payload.(*JoinPayload)
+	require.Equal(t, "Alex", jp.Name)
+	require.Equal(t, 2, jp.ProtocolVersion)
+}
+
+func TestDecodeClientPayload_EmptyPayload(t *testing.T) {
+	_, err := decodeClientPayload("start_game", nil)
+	require.NoError(t, err)
+
+	_, err = decodeClientPayload("start_game", json.RawMessage(`null`))
+	require.NoError(t, err)
+}
+
+func TestDecodeClientPayload_UnknownField(t *testing.T) {
+	_, err := decodeClientPayload("submit_answer", json.RawMessage(`{"optionId":"B","cheat":true}`))
+
+	var perr *PayloadError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, "unknown_field", perr.Code)
+	require.Equal(t, "cheat", perr.Field)
+	require.Equal(t, "submit_answer", perr.Type)
+}
+
+func TestDecodeClientPayload_WrongType(t *testing.T) {
+	_, err := decodeClientPayload("set_ready", json.RawMessage(`{"ready":"yes"}`))
+
+	var perr *PayloadError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, "wrong_type", perr.Code)
+	require.Equal(t, "ready", perr.Field)
+}
+
+func TestDecodeClientPayload_UnknownType(t *testing.T) {
+	_, err := decodeClientPayload("teleport", json.RawMessage(`{}`))
+	require.ErrorIs(t, err, ErrUnknownMessageType)
+
+	_, err = decodeClientPayload("room_state", json.RawMessage(`{}`))
+	require.ErrorIs(t, err, ErrUnknownMessageType)
+}
+
+func TestJSONSchema_CoversCatalog(t *testing.T) {
+	raw, err := JSONSchema()
+	require.NoError(t, err)
+
+	var doc struct {
+		Defs map[string]json.RawMessage `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &doc))
+
+	var client, server struct {
+		OneOf []struct {
+			Properties struct {
+				Type struct {
+					Const string `json:"const"`
+				} `json:"type"`
+			} `json:"properties"`
+		} `json:"oneOf"`
+	}
+	require.NoError(t, json.Unmarshal(doc.Defs["ClientMessage"], &client))
+	require.NoError(t, json.Unmarshal(doc.Defs["ServerMessage"], &server))
+	require.Len(t, client.OneOf, len(clientMessages))
+	require.Len(t, server.OneOf, len(Catalog)-len(clientMessages))
+
+	require.Contains(t, doc.Defs, "VersionedRoomState")
+	require.Contains(t, doc.Defs, "JoinPayload")
+	require.NotContains(t, string(doc.Defs["Player"]), "ResumeToken")
+}
+
+func TestDecodeClientPayload_TimeSync(t *testing.T) {
+	payload, err := decodeClientPayload("time_sync", json.RawMessage(`{"clientTime":1700000000123}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(1700000000123), payload.(*TimeSyncPayload).ClientTime)
+}
@@ -2,7 +2,8 @@ package ws
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/ArtemMoroz51/FinalProject/internal/game"
@@ -14,12 +15,36 @@ type Client struct {
 	hub      *Hub
 	roomCode string
 	playerID string
+	session  int
+	version  int
+	binary   bool
+	role     string
 	conn     *websocket.Conn
 	send     chan []byte
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMsg  []byte
+
+	resyncOnFull bool
+	syncMu       sync.Mutex
+	stale        bool
+	queuedSeq    uint64
+	droppedSeq   uint64
+
+	reactions *tokenBucket
+	limiter   *inboundLimiter
+}
+
+func (c *Client) frameKey() frameKey {
+	return frameKey{version: c.version, binary: c.binary, controller: c.role == RoleController}
 }
 
 func (c *Client) sendJSON(env Envelope) {
-	b, err := json.Marshal(env)
+	b, err := encodeForVersion(env, c.version)
+	if err == nil {
+		b, err = c.frameKey().encode(b)
+	}
 	if err != nil {
 		c.hub.log.Error("ws send marshal failed",
 			zap.String("room", c.roomCode),
@@ -28,36 +53,67 @@ func (c *Client) sendJSON(env Envelope) {
 		)
 		return
 	}
+	c.sendRaw(b)
+}
+
+func (c *Client) sendRaw(b []byte) {
+	if !c.deliver(b, 0) {
+		c.hub.dropClient(c)
+	}
+}
+
+func (c *Client) deliver(b []byte, seq uint64) bool {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	if seq != 0 && seq <= c.queuedSeq {
+		return true
+	}
+	if seq != 0 && c.stale {
+		c.droppedSeq = seq
+		return true
+	}
 	select {
+	case <-c.done:
+		return true
 	case c.send <- b:
+		if seq != 0 {
+			c.queuedSeq = seq
+		}
+		return true
 	default:
-		c.hub.unregister <- c
 	}
+	if c.resyncOnFull {
+		c.stale = true
+		if seq != 0 {
+			c.droppedSeq = seq
+		}
+		return true
+	}
+	return false
 }
 
-func (c *Client) readPump(room *game.Room) {
-	defer func() {
-		room.RemovePlayer(c.playerID)
-		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
-		c.hub.unregister <- c
-		_ = c.conn.Close()
+func (c *Client) close(closeMsg []byte) {
+	c.closeOnce.Do(func() {
+		c.closeMsg = closeMsg
+		close(c.done)
+	})
+}
 
-		c.hub.log.Info("ws connection closed",
-			zap.String("room", c.roomCode),
-			zap.String("player_id", c.playerID),
-		)
-	}()
+func (c *Client) readPump(room *game.Room) {
+	defer c.disconnect(room)
 
-	c.conn.SetReadLimit(maxMessageSize)
-	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cfg := c.hub.cfg
+	c.conn.SetReadLimit(cfg.MaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 	c.conn.SetPongHandler(func(string) error {
-		_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		_ = c.conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
 		return nil
 	})
 
 	for {
-		var msg clientMsg
-		if err := c.conn.ReadJSON(&msg); err != nil {
+		msg, err := readClientMsg(c.conn)
+		if err != nil {
 			c.hub.log.Warn("ws read failed",
 				zap.String("room", c.roomCode),
 				zap.String("player_id", c.playerID),
@@ -65,75 +121,422 @@ func (c *Client) readPump(room *game.Room) {
 			)
 			break
 		}
+		if !c.handle(room, msg) {
+			break
+		}
+	}
+}
+
+func (c *Client) disconnect(room *game.Room) {
+	if c.role == RoleDisplay || c.role == RoleSpectator {
+		if room.RemoveDisplay(c.playerID) || room.RemoveSpectator(c.playerID) {
+			c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+		}
+	} else if room.DisconnectPlayer(c.playerID, c.session) {
+		c.hub.recheckReadyQuorum(room, c.roomCode)
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+		c.hub.finishRoundIfAllAnswered(room, c.roomCode)
+		go c.hub.scheduleRemoval(room, c.roomCode, c.playerID, c.session)
+	}
+	c.hub.unregister <- c
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+
+	c.hub.log.Info("ws connection closed",
+		zap.String("room", c.roomCode),
+		zap.String("player_id", c.playerID),
+	)
+}
+
+func (c *Client) handle(room *game.Room, msg clientMsg) bool {
+	receivedAt := time.Now()
+	c.hub.counters.received.Add(1)
+
+	c.hub.log.Debug("ws message received",
+		zap.String("room", c.roomCode),
+		zap.String("player_id", c.playerID),
+		zap.String("type", msg.Type),
+	)
+
+	if action := c.allowInbound(msg.Type, receivedAt); action == limitDisconnect {
+		return false
+	} else if action != limitAllow {
+		return true
+	}
 
-		c.hub.log.Info("ws message received",
+	payload, err := decodeClientPayload(msg.Type, msg.Payload)
+	if err != nil {
+		c.hub.counters.rejected.Add(1)
+		c.hub.log.Warn("ws message rejected",
 			zap.String("room", c.roomCode),
 			zap.String("player_id", c.playerID),
 			zap.String("type", msg.Type),
+			zap.Error(err),
 		)
+		var perr *PayloadError
+		if errors.As(err, &perr) {
+			c.sendJSON(perr.Envelope())
+		} else {
+			c.sendJSON(errorEnvelope(err.Error()))
+		}
+		return true
+	}
 
-		switch msg.Type {
-		case "start_game":
-			snap := room.Snapshot()
-			if snap.RoundNumber >= c.hub.svc.MaxRounds() {
-				gameOver := c.hub.svc.BuildLeaderboard(room)
-				c.sendJSON(Envelope{Type: "game_over", Payload: gameOver})
-				continue
-			}
+	if c.role == RoleSpectator && !spectatorMessages[msg.Type] {
+		c.sendJSON(Envelope{Type: "error", Payload: ErrorPayload{Message: game.ErrSpectator.Error(), Code: "spectator", Type: msg.Type}})
+		return true
+	}
 
-			if err := c.hub.svc.StartRound(context.Background(), room, c.playerID); err != nil {
-				c.hub.log.Warn("start_game failed",
-					zap.String("room", c.roomCode),
-					zap.String("player_id", c.playerID),
-					zap.Error(err),
-				)
-				c.sendJSON(Envelope{Type: "error", Payload: map[string]string{"message": err.Error()}})
-				continue
+	switch msg.Type {
+	case "start_game", "next_round":
+		snap := room.Snapshot()
+		if msg.Type == "next_round" {
+			if err := checkNextRound(snap, room.CanControl(c.playerID)); err != nil {
+				c.sendJSON(errorEnvelope(err.Error()))
+				return true
 			}
+		}
+		if snap.RoundNumber >= c.hub.svc.MaxRounds() {
+			gameOver := c.hub.svc.BuildLeaderboard(room)
+			c.sendJSON(Envelope{Type: "game_over", Payload: gameOver})
+			return true
+		}
 
-			c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+		if err := c.hub.svc.StartRound(context.Background(), room, c.playerID); err != nil {
+			c.hub.log.Warn("start_game failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.String("type", msg.Type),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
 
-			gen := c.hub.bumpRoundGen(c.roomCode)
-			go c.hub.scheduleAnsweringDeadline(room, c.roomCode, gen)
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
 
-		case "submit_answer":
-			var p SubmitAnswerPayload
-			if err := json.Unmarshal(msg.Payload, &p); err != nil {
-				c.hub.log.Warn("submit_answer bad payload",
-					zap.String("room", c.roomCode),
-					zap.String("player_id", c.playerID),
-					zap.Error(err),
-				)
-				c.sendJSON(Envelope{Type: "error", Payload: map[string]string{"message": "bad payload"}})
-				continue
-			}
+		gen := c.hub.bumpRoundGen(c.roomCode)
+		go c.hub.scheduleAnsweringDeadline(room, c.roomCode, gen)
 
-			if err := room.SubmitAnswer(c.playerID, p.OptionID); err != nil {
-				c.hub.log.Warn("submit_answer failed",
-					zap.String("room", c.roomCode),
-					zap.String("player_id", c.playerID),
-					zap.String("option_id", p.OptionID),
-					zap.Error(err),
-				)
-				c.sendJSON(Envelope{Type: "error", Payload: map[string]string{"message": err.Error()}})
-				continue
+	case "reveal_answer":
+		payload, err := room.RevealAnswer(c.playerID)
+		if err != nil {
+			c.hub.log.Warn("reveal_answer failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		gen := c.hub.bumpRoundGen(c.roomCode)
+		c.hub.roundFinished(room, c.roomCode, payload, gen)
+
+	case "set_ready":
+		p := payload.(*SetReadyPayload)
+
+		started, err := c.hub.svc.SetReady(room, c.playerID, p.Ready)
+		if err != nil {
+			c.hub.log.Warn("set_ready failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+		if started || !p.Ready {
+			gen := c.hub.bumpRoundGen(c.roomCode)
+			if started {
+				go c.hub.scheduleCountdown(room, c.roomCode, gen)
 			}
+		}
 
-			c.sendJSON(Envelope{Type: "answer_accepted", Payload: map[string]bool{"ok": true}})
+	case "end_game":
+		gameOver, err := c.hub.svc.EndGame(room, c.playerID)
+		if err != nil {
+			c.hub.log.Warn("end_game failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
 
-		default:
-			c.hub.log.Warn("unknown ws message type",
+		c.hub.CloseRoom(c.roomCode, gameOver)
+
+	case "play_again", "reset_game":
+		if err := c.hub.svc.ResetGame(room, c.playerID); err != nil {
+			c.hub.log.Warn("play_again failed",
 				zap.String("room", c.roomCode),
 				zap.String("player_id", c.playerID),
-				zap.String("type", msg.Type),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.bumpRoundGen(c.roomCode)
+
+		snap := room.Snapshot()
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "game_reset", Payload: snap.PreviousGame})
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: snap})
+
+	case "pause_game":
+		if err := room.Pause(c.playerID); err != nil {
+			c.hub.log.Warn("pause_game failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.bumpRoundGen(c.roomCode)
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+	case "resume_game":
+		if err := room.Resume(c.playerID); err != nil {
+			c.hub.log.Warn("resume_game failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+		c.hub.resumeSchedule(room, c.roomCode)
+
+	case "submit_answer":
+		p := payload.(*SubmitAnswerPayload)
+
+		if err := room.SubmitAnswer(c.playerID, p.OptionID); err != nil {
+			c.hub.log.Warn("submit_answer failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.String("option_id", p.OptionID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.sendJSON(Envelope{Type: "answer_accepted", Payload: AnswerAcceptedPayload{OK: true}})
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "answer_progress", Payload: room.AnswerProgress()})
+		c.hub.finishRoundIfAllAnswered(room, c.roomCode)
+
+	case "update_settings":
+		p := payload.(*UpdateSettingsPayload)
+
+		settings := room.Snapshot().Settings
+		wasHostPaced := settings.HostPaced
+		if p.EndRoundEarly != nil {
+			settings.EndRoundEarly = *p.EndRoundEarly
+		}
+		if p.HostPaced != nil {
+			settings.HostPaced = *p.HostPaced
+		}
+		if p.ChatDuringAnswering != nil {
+			settings.ChatDuringAnswering = *p.ChatDuringAnswering
+		}
+		if p.RequireDisplay != nil {
+			settings.RequireDisplay = *p.RequireDisplay
+		}
+
+		if err := room.UpdateSettings(c.playerID, settings); err != nil {
+			c.hub.log.Warn("update_settings failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
 			)
-			c.sendJSON(Envelope{Type: "error", Payload: map[string]string{"message": "unknown message type"}})
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+		c.hub.finishRoundIfAllAnswered(room, c.roomCode)
+		if wasHostPaced && !settings.HostPaced {
+			c.hub.resumeSchedule(room, c.roomCode)
+		}
+
+	case "sync":
+		p := payload.(*SyncPayload)
+
+		events, _, ok := c.hub.replaySince(c.roomCode, c.playerID, p.LastSeq, c.frameKey())
+		if !ok {
+			c.sendFullState(room)
+			return true
+		}
+		for _, e := range events {
+			c.sendRaw(e)
 		}
+
+	case "chat_send":
+		p := payload.(*ChatSendPayload)
+
+		chatMsg, err := c.hub.svc.SendChat(room, c.playerID, p.Text)
+		if err != nil {
+			c.hub.log.Warn("chat_send failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "chat_message", Payload: chatMsg})
+
+	case "mute_player":
+		p := payload.(*MutePlayerPayload)
+
+		if err := c.hub.svc.MutePlayer(room, c.playerID, p.PlayerID, p.Muted); err != nil {
+			c.hub.log.Warn("mute_player failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.String("target_id", p.PlayerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+	case "react":
+		p := payload.(*ReactPayload)
+
+		if err := c.react(room, p.Emoji); err != nil {
+			c.hub.log.Debug("react rejected",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+	case "time_sync":
+		p := payload.(*TimeSyncPayload)
+
+		c.sendJSON(Envelope{Type: "time_sync", Payload: TimeSyncReplyPayload{
+			ClientTime:    p.ClientTime,
+			ServerReceive: receivedAt.UnixMilli(),
+			ServerSend:    time.Now().UnixMilli(),
+		}})
+
+	case "get_state":
+		c.sendFullState(room)
+
+	case "transfer_host":
+		p := payload.(*TransferHostPayload)
+
+		oldHostID := room.Snapshot().HostID
+		if err := room.TransferHost(c.playerID, p.PlayerID); err != nil {
+			c.hub.log.Warn("transfer_host failed",
+				zap.String("room", c.roomCode),
+				zap.String("player_id", c.playerID),
+				zap.String("target_id", p.PlayerID),
+				zap.Error(err),
+			)
+			c.sendJSON(errorEnvelope(err.Error()))
+			return true
+		}
+
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "host_changed", Payload: HostChangedPayload{
+			OldHostID: oldHostID,
+			NewHostID: p.PlayerID,
+		}})
+		c.hub.Broadcast(c.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+	default:
+		c.hub.log.Warn("unknown ws message type",
+			zap.String("room", c.roomCode),
+			zap.String("player_id", c.playerID),
+			zap.String("type", msg.Type),
+		)
+		c.sendJSON(errorEnvelope("unknown message type"))
+	}
+	return true
+}
+
+func (c *Client) allowInbound(msgType string, now time.Time) limitAction {
+	if c.limiter == nil {
+		return limitAllow
+	}
+
+	action := c.limiter.check(msgType, now)
+	if action == limitAllow {
+		return action
+	}
+	c.hub.counters.rateLimited.Add(1)
+
+	switch action {
+	case limitError:
+		c.sendJSON(rateLimitedEnvelope(msgType))
+	case limitThrottle:
+		c.hub.counters.throttled.Add(1)
+		time.Sleep(c.limiter.limits.ThrottleDelay)
+		c.sendJSON(rateLimitedEnvelope(msgType))
+	case limitDisconnect:
+		c.hub.counters.policyDisconnects.Add(1)
+		c.hub.log.Warn("ws client disconnected for flooding",
+			zap.String("room", c.roomCode),
+			zap.String("player_id", c.playerID),
+			zap.String("type", msgType),
+		)
+		if c.conn != nil {
+			_ = c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "rate limit exceeded"),
+				time.Now().Add(c.hub.cfg.WriteWait),
+			)
+		}
+	}
+	return action
+}
+
+func rateLimitedEnvelope(msgType string) Envelope {
+	return Envelope{Type: "error", Payload: ErrorPayload{Message: "rate limited", Code: "rate_limited", Type: msgType}}
+}
+
+func (c *Client) sendFullState(room *game.Room) {
+	b, err := c.hub.fullState(c.roomCode, room, c.frameKey())
+	if err != nil {
+		c.hub.log.Error("ws full state failed",
+			zap.String("room", c.roomCode),
+			zap.String("player_id", c.playerID),
+			zap.Error(err),
+		)
+		return
 	}
+	c.sendRaw(b)
+}
+
+func checkNextRound(snap game.RoomSnapshot, canControl bool) error {
+	if !canControl {
+		return game.ErrNotHost
+	}
+	if !snap.Settings.HostPaced {
+		return game.ErrNotHostPaced
+	}
+	if snap.Phase != game.PhaseResults {
+		return game.ErrBadPhase
+	}
+	return nil
 }
 
 func (c *Client) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	cfg := c.hub.cfg
+	ticker := time.NewTicker(cfg.PingPeriod)
 	defer func() {
 		ticker.Stop()
 		_ = c.conn.Close()
@@ -141,13 +544,9 @@ func (c *Client) writePump() {
 
 	for {
 		select {
-		case message, ok := <-c.send:
-			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+			if err := c.writeFrame(message); err != nil {
 				c.hub.log.Warn("ws write failed",
 					zap.String("room", c.roomCode),
 					zap.String("player_id", c.playerID),
@@ -156,8 +555,18 @@ func (c *Client) writePump() {
 				return
 			}
 
+		case <-c.done:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+			for _, message := range c.pending() {
+				if err := c.writeFrame(message); err != nil {
+					return
+				}
+			}
+			c.writeClose()
+			return
+
 		case <-ticker.C:
-			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			_ = c.conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				c.hub.log.Warn("ws ping failed",
 					zap.String("room", c.roomCode),
@@ -169,3 +578,30 @@ func (c *Client) writePump() {
 		}
 	}
 }
+
+func (c *Client) writeFrame(message []byte) error {
+	if c.hub.cfg.EnableCompression {
+		c.conn.EnableWriteCompression(len(message) >= c.hub.cfg.CompressionThreshold)
+	}
+	return c.conn.WriteMessage(c.frameKey().messageType(), message)
+}
+
+func (c *Client) writeClose() {
+	closeMsg := c.closeMsg
+	if closeMsg == nil {
+		closeMsg = []byte{}
+	}
+	_ = c.conn.WriteMessage(websocket.CloseMessage, closeMsg)
+}
+
+func (c *Client) pending() [][]byte {
+	var out [][]byte
+	for {
+		select {
+		case message := <-c.send:
+			out = append(out, message)
+		default:
+			return out
+		}
+	}
+}
@@ -6,9 +6,108 @@ const (
 	answeringSeconds = 30 * time.Second
 	resultsPause     = 5 * time.Second
 	maxRounds        = 5
+	reconnectGrace   = 30 * time.Second
+	eventHistorySize = 128
+
+	reactionInterval = 500 * time.Millisecond
+	reactionBurst    = 3
+	reactionRate     = 1.0
 
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
 	maxMessageSize = 8 * 1024
+	joinTimeout    = 15 * time.Second
+	sendBufferSize = 64
+
+	handshakeTimeout     = 10 * time.Second
+	compressionThreshold = 1024
 )
+
+type Config struct {
+	AllowedOrigins []string
+
+	EnableCompression    bool
+	CompressionThreshold int
+
+	ReadBufferSize   int
+	WriteBufferSize  int
+	HandshakeTimeout time.Duration
+
+	WriteWait      time.Duration
+	PongWait       time.Duration
+	PingPeriod     time.Duration
+	MaxMessageSize int64
+	JoinTimeout    time.Duration
+	SendBufferSize int
+	ReconnectGrace time.Duration
+
+	RateLimits RateLimits
+}
+
+func DefaultConfig() Config {
+	return Config{
+		CompressionThreshold: compressionThreshold,
+		HandshakeTimeout:     handshakeTimeout,
+		WriteWait:            writeWait,
+		PongWait:             pongWait,
+		PingPeriod:           pingPeriod,
+		MaxMessageSize:       maxMessageSize,
+		JoinTimeout:          joinTimeout,
+		SendBufferSize:       sendBufferSize,
+		ReconnectGrace:       reconnectGrace,
+		RateLimits:           DefaultRateLimits(),
+	}
+}
+
+func (cfg Config) withDefaults() Config {
+	def := DefaultConfig()
+	if cfg.CompressionThreshold <= 0 {
+		cfg.CompressionThreshold = def.CompressionThreshold
+	}
+	if cfg.HandshakeTimeout <= 0 {
+		cfg.HandshakeTimeout = def.HandshakeTimeout
+	}
+	if cfg.WriteWait <= 0 {
+		cfg.WriteWait = def.WriteWait
+	}
+	if cfg.PongWait <= 0 {
+		cfg.PongWait = def.PongWait
+	}
+	if cfg.PingPeriod <= 0 || cfg.PingPeriod >= cfg.PongWait {
+		cfg.PingPeriod = (cfg.PongWait * 9) / 10
+	}
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = def.MaxMessageSize
+	}
+	if cfg.JoinTimeout <= 0 {
+		cfg.JoinTimeout = def.JoinTimeout
+	}
+	if cfg.SendBufferSize <= 0 {
+		cfg.SendBufferSize = def.SendBufferSize
+	}
+	if cfg.ReconnectGrace <= 0 {
+		cfg.ReconnectGrace = def.ReconnectGrace
+	}
+
+	limits := &cfg.RateLimits
+	if limits.Connection.Burst <= 0 || limits.Connection.PerSecond <= 0 {
+		limits.Connection = def.RateLimits.Connection
+	}
+	if limits.PerType == nil {
+		limits.PerType = def.RateLimits.PerType
+	}
+	if limits.ErrorsBeforeThrottle <= 0 {
+		limits.ErrorsBeforeThrottle = def.RateLimits.ErrorsBeforeThrottle
+	}
+	if limits.ViolationsBeforeDisconnect <= 0 {
+		limits.ViolationsBeforeDisconnect = def.RateLimits.ViolationsBeforeDisconnect
+	}
+	if limits.ThrottleDelay <= 0 {
+		limits.ThrottleDelay = def.RateLimits.ThrottleDelay
+	}
+	if limits.ViolationWindow <= 0 {
+		limits.ViolationWindow = def.RateLimits.ViolationWindow
+	}
+	return cfg
+}
@@ -0,0 +1,104 @@
+package ws
+
+import (
+	"errors"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+)
+
+const (
+	RolePlayer     = "player"
+	RoleController = "controller"
+	RoleDisplay    = "display"
+	RoleSpectator  = "spectator"
+)
+
+var ErrInvalidRole = errors.New("invalid role")
+
+var spectatorMessages = map[string]bool{
+	"sync":      true,
+	"get_state": true,
+	"time_sync": true,
+	"react":     true,
+}
+
+type ControllerPlayer struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Avatar   string `json:"avatar"`
+	Color    string `json:"color"`
+	Ready    bool   `json:"ready"`
+	Online   bool   `json:"online"`
+	Score    int    `json:"score"`
+	Answered bool   `json:"answered,omitempty"`
+}
+
+type ControllerState struct {
+	Code        string             `json:"code"`
+	Phase       game.Phase         `json:"phase"`
+	HostID      string             `json:"hostId"`
+	RoundNumber int                `json:"roundNumber"`
+	Settings    game.RoomSettings  `json:"settings"`
+	Options     []game.Option      `json:"options,omitempty"`
+	Deadline    int64              `json:"deadline,omitempty"`
+	RemainingMs int64              `json:"remainingMs,omitempty"`
+	Paused      bool               `json:"paused"`
+	Displays    int                `json:"displays"`
+	Spectators  int                `json:"spectators"`
+	Players     []ControllerPlayer `json:"players"`
+}
+
+func validRole(role string) bool {
+	switch role {
+	case "", RolePlayer, RoleController, RoleDisplay, RoleSpectator:
+		return true
+	default:
+		return false
+	}
+}
+
+func isPlayerRole(role string) bool {
+	return role != RoleDisplay && role != RoleSpectator
+}
+
+func controllerState(snap game.RoomSnapshot) ControllerState {
+	players := make([]ControllerPlayer, 0, len(snap.Players))
+	for _, p := range snap.Players {
+		players = append(players, ControllerPlayer{
+			ID:       p.ID,
+			Name:     p.Name,
+			Avatar:   p.Avatar,
+			Color:    p.Color,
+			Ready:    p.Ready,
+			Online:   p.Online,
+			Score:    snap.Scores[p.ID],
+			Answered: snap.Answered[p.ID],
+		})
+	}
+
+	state := ControllerState{
+		Code:        snap.Code,
+		Phase:       snap.Phase,
+		HostID:      snap.HostID,
+		RoundNumber: snap.RoundNumber,
+		Settings:    snap.Settings,
+		Deadline:    snap.Deadline,
+		RemainingMs: snap.RemainingMs,
+		Paused:      snap.Paused,
+		Displays:    snap.Displays,
+		Spectators:  snap.Spectators,
+		Players:     players,
+	}
+	if snap.Phase == game.PhaseAnswering {
+		state.Options = snap.Options
+	}
+	return state
+}
+
+func controllerEnvelope(env Envelope) (Envelope, bool) {
+	snap, ok := env.Payload.(game.RoomSnapshot)
+	if !ok || env.Type != "room_state" {
+		return env, false
+	}
+	return Envelope{Type: "controller_state", Seq: env.Seq, Payload: controllerState(snap)}, true
+}
@@ -0,0 +1,112 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHub_Broadcast_ControllerGetsTrimmedState(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	room := roomWithPlayers(2)
+
+	full := registerTestClient(t, h, "ABCD", "tv", ProtocolV2)
+	phone := registerTestClient(t, h, "ABCD", "p0", ProtocolV2)
+	phone.role = RoleController
+
+	h.Broadcast("ABCD", Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+	env := receiveType(t, full)
+	require.Equal(t, "room_state", env["type"])
+	require.Contains(t, env["payload"], "scores")
+
+	env = receiveType(t, phone)
+	require.Equal(t, "controller_state", env["type"])
+	require.Equal(t, float64(1), env["seq"])
+	payload := env["payload"].(map[string]interface{})
+	require.NotContains(t, payload, "scores")
+	require.NotContains(t, payload, "question")
+	require.Len(t, payload["players"], 2)
+
+	h.Broadcast("ABCD", Envelope{Type: "answer_accepted", Payload: AnswerAcceptedPayload{OK: true}})
+	require.Equal(t, "answer_accepted", receiveType(t, phone)["type"])
+
+	events, _, ok := h.replaySince("ABCD", "", 0, phone.frameKey())
+	require.True(t, ok)
+	require.Contains(t, string(events[0]), "controller_state")
+	require.Contains(t, string(events[1]), "answer_accepted")
+
+	b, err := h.fullState("ABCD", room, phone.frameKey())
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"type":"controller_state"`)
+}
+
+func TestControllerState_OptionsOnlyWhileAnswering(t *testing.T) {
+	snap := game.RoomSnapshot{
+		Phase:   game.PhaseResults,
+		Options: []game.Option{{ID: "A", Text: "A"}},
+		Players: []*game.Player{{ID: "p1", Name: "Alex"}},
+		Scores:  map[string]int{"p1": 3},
+	}
+	state := controllerState(snap)
+	require.Empty(t, state.Options)
+	require.Equal(t, 3, state.Players[0].Score)
+
+	snap.Phase = game.PhaseAnswering
+	snap.Answered = map[string]bool{"p1": true}
+	state = controllerState(snap)
+	require.Len(t, state.Options, 1)
+	require.True(t, state.Players[0].Answered)
+}
+
+func TestSSE_DisplayJoinIsNotAPlayer(t *testing.T) {
+	_, room, srv := newSSEServer(t, Config{})
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Alex"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"TV","protocolVersion":2,"role":"display"}}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var rejected map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rejected))
+	require.Equal(t, "bad_host_token", rejected["payload"].(map[string]interface{})["code"])
+	require.Zero(t, room.Snapshot().Displays)
+
+	resp = postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"TV","protocolVersion":2,"role":"display","hostToken":"`+room.HostToken+`"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var display JoinResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&display))
+
+	snap := room.Snapshot()
+	require.Equal(t, 1, snap.Displays)
+	require.Len(t, snap.Players, 1)
+	require.NotEqual(t, display.PlayerID, snap.HostID)
+
+	events, err := http.Get(srv.URL + "/events?session=" + display.SessionID)
+	require.NoError(t, err)
+	defer events.Body.Close()
+	stream := bufio.NewReader(events.Body)
+	require.Equal(t, RoleDisplay, nextEvent(t, stream, "joined")["payload"].(map[string]interface{})["role"])
+
+	resp = postJSON(t, srv.URL+"/actions", display.SessionID, `{"type":"update_settings","payload":{"requireDisplay":true}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	for {
+		state := nextEvent(t, stream, "room_state")["payload"].(map[string]interface{})
+		if state["settings"].(map[string]interface{})["requireDisplay"] == true {
+			break
+		}
+	}
+	require.True(t, room.Snapshot().Settings.RequireDisplay)
+
+	resp = postJSON(t, srv.URL+"/actions", display.SessionID, `{"type":"set_ready","payload":{"ready":true}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.Equal(t, game.ErrPlayerNotFound.Error(), nextEvent(t, stream, "error")["payload"].(map[string]interface{})["message"])
+
+	resp = postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Bad","role":"judge"}}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -1,13 +1,14 @@
 package ws
 
 import (
-	"encoding/json"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ArtemMoroz51/FinalProject/internal/game"
 	"github.com/ArtemMoroz51/FinalProject/internal/service"
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
@@ -24,14 +25,54 @@ type Hub struct {
 
 	roundGenMu sync.Mutex
 	roundGen   map[string]int64
+
+	historyMu sync.Mutex
+	history   map[string]*roomHistory
+	closed    map[string]bool
+
+	reactionsMu sync.Mutex
+	reactions   map[string]*reactionBatch
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*httpSession
+
+	cfg      Config
+	upgrader websocket.Upgrader
+	counters counters
+}
+
+type roomHistory struct {
+	mu     sync.Mutex
+	seq    uint64
+	events []sequencedEvent
+	state  *roomState
+
+	outbox   []roomMessage
+	flushing bool
+}
+
+type sequencedEvent struct {
+	seq      uint64
+	frames   *frames
+	personal map[string]personalFrame
+}
+
+type personalFrame struct {
+	key   frameKey
+	frame []byte
 }
 
 type roomMessage struct {
-	roomCode string
-	data     []byte
+	roomCode  string
+	frames    *frames
+	perPlayer map[string]personalFrame
+	only      string
+	closeRoom []byte
 }
 
-func NewHub(svc service.GameService, log *zap.Logger) *Hub {
+type Personalize = service.Personalize
+
+func NewHub(svc service.GameService, log *zap.Logger, cfg Config) *Hub {
 	if log == nil {
 		log = zap.NewNop()
 	}
@@ -43,18 +84,271 @@ func NewHub(svc service.GameService, log *zap.Logger) *Hub {
 		unregister:    make(chan *Client),
 		broadcast:     make(chan roomMessage, 256),
 		roundGen:      make(map[string]int64),
+		history:       make(map[string]*roomHistory),
+		closed:        make(map[string]bool),
+		reactions:     make(map[string]*reactionBatch),
+		sessions:      make(map[string]*httpSession),
+		cfg:           cfg.withDefaults(),
 	}
+	h.upgrader = newUpgrader(h.cfg, h)
 	go h.run()
 	return h
 }
 
 func (h *Hub) Broadcast(roomCode string, env Envelope) {
-	b, err := json.Marshal(env)
-	if err != nil {
-		h.log.Error("ws broadcast marshal failed", zap.Error(err))
+	h.broadcastEach(roomCode, env, nil)
+}
+
+func (h *Hub) BroadcastEach(roomCode, msgType string, payload interface{}, personalize Personalize) {
+	h.broadcastEach(roomCode, Envelope{Type: msgType, Payload: payload}, personalize)
+}
+
+func (h *Hub) broadcastEach(roomCode string, env Envelope, personalize Personalize) {
+	rc := strings.ToUpper(roomCode)
+	hist := h.roomHistory(rc)
+	if hist == nil {
 		return
 	}
-	h.broadcast <- roomMessage{roomCode: roomCode, data: b}
+
+	hist.mu.Lock()
+	env.Seq = hist.seq + 1
+	f := newFrames(env)
+	if snap, ok := env.Payload.(game.RoomSnapshot); ok && env.Type == "room_state" {
+		f.setState(hist.nextState(env.Seq, snap))
+	}
+	hist.seq = env.Seq
+
+	msg := roomMessage{roomCode: rc, frames: f}
+	if personalize != nil {
+		msg.perPlayer = h.personalFrames(rc, env, personalize)
+	}
+
+	hist.events = append(hist.events, sequencedEvent{seq: env.Seq, frames: f, personal: msg.perPlayer})
+	if len(hist.events) > eventHistorySize {
+		hist.events = hist.events[len(hist.events)-eventHistorySize:]
+	}
+	flush := hist.queueLocked(msg)
+	hist.mu.Unlock()
+
+	if flush {
+		h.flushOutbox(hist)
+	}
+}
+
+func (h *Hub) post(msg roomMessage) {
+	hist := h.roomHistory(msg.roomCode)
+	if hist == nil {
+		return
+	}
+
+	hist.mu.Lock()
+	flush := hist.queueLocked(msg)
+	hist.mu.Unlock()
+
+	if flush {
+		h.flushOutbox(hist)
+	}
+}
+
+func (hist *roomHistory) queueLocked(msg roomMessage) (flush bool) {
+	hist.outbox = append(hist.outbox, msg)
+	flush = !hist.flushing
+	hist.flushing = true
+	return flush
+}
+
+func (h *Hub) roomHistory(roomCode string) *roomHistory {
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+
+	hist, ok := h.history[roomCode]
+	if !ok {
+		if h.closed[roomCode] {
+			return nil
+		}
+		hist = &roomHistory{}
+		h.history[roomCode] = hist
+	}
+	return hist
+}
+
+func (h *Hub) flushOutbox(hist *roomHistory) {
+	for {
+		hist.mu.Lock()
+		batch := hist.outbox
+		hist.outbox = nil
+		if len(batch) == 0 {
+			hist.flushing = false
+		}
+		hist.mu.Unlock()
+
+		if len(batch) == 0 {
+			return
+		}
+		for _, msg := range batch {
+			h.broadcast <- msg
+		}
+	}
+}
+
+func (h *Hub) personalFrames(roomCode string, env Envelope, personalize Personalize) map[string]personalFrame {
+	h.mu.RLock()
+	keys := make(map[string]frameKey, len(h.clientsByRoom[roomCode]))
+	for id, c := range h.clientsByRoom[roomCode] {
+		keys[id] = c.frameKey()
+	}
+	h.mu.RUnlock()
+
+	out := make(map[string]personalFrame, len(keys))
+	for id, key := range keys {
+		if key.version < ProtocolV2 {
+			continue
+		}
+		payload := personalize(id)
+		if payload == nil {
+			continue
+		}
+		b, err := encodeForVersion(Envelope{Type: env.Type, Seq: env.Seq, Payload: payload}, key.version)
+		if err == nil {
+			b, err = key.encode(b)
+		}
+		if err != nil {
+			h.log.Error("ws personalized marshal failed",
+				zap.String("room", roomCode),
+				zap.String("player_id", id),
+				zap.Error(err),
+			)
+			continue
+		}
+		out[id] = personalFrame{key: key, frame: b}
+	}
+	return out
+}
+
+func (h *Hub) broadcastEphemeral(roomCode string, env Envelope) {
+	h.post(roomMessage{roomCode: strings.ToUpper(roomCode), frames: newFrames(env)})
+}
+
+func (h *Hub) SendTo(roomCode, playerID, msgType string, payload interface{}) bool {
+	rc := strings.ToUpper(roomCode)
+
+	h.mu.RLock()
+	_, ok := h.clientsByRoom[rc][playerID]
+	h.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	h.post(roomMessage{roomCode: rc, frames: newFrames(Envelope{Type: msgType, Payload: payload}), only: playerID})
+	return true
+}
+
+func (h *Hub) replaySince(roomCode, playerID string, lastSeq uint64, key frameKey) (events [][]byte, currentSeq uint64, ok bool) {
+	h.historyMu.Lock()
+	hist, exists := h.history[strings.ToUpper(roomCode)]
+	h.historyMu.Unlock()
+	if !exists {
+		return nil, 0, lastSeq == 0
+	}
+
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+
+	if lastSeq > hist.seq {
+		return nil, hist.seq, false
+	}
+	if lastSeq == hist.seq {
+		return nil, hist.seq, true
+	}
+	if len(hist.events) == 0 || hist.events[0].seq > lastSeq+1 {
+		return nil, hist.seq, false
+	}
+
+	now := time.Now()
+	for _, e := range hist.events {
+		if e.seq <= lastSeq {
+			continue
+		}
+		if pf, ok := e.personal[playerID]; ok && pf.key == key {
+			events = append(events, pf.frame)
+			continue
+		}
+		b, err := e.frames.get(key, now)
+		if err != nil {
+			return nil, hist.seq, false
+		}
+		events = append(events, b)
+	}
+	return events, hist.seq, true
+}
+
+func (h *Hub) fullState(roomCode string, room *game.Room, key frameKey) ([]byte, error) {
+	b, _, err := h.fullStateAt(roomCode, room, key)
+	return b, err
+}
+
+func (h *Hub) fullStateAt(roomCode string, room *game.Room, key frameKey) ([]byte, uint64, error) {
+	hist := h.roomHistory(strings.ToUpper(roomCode))
+	if hist == nil {
+		return nil, 0, game.ErrRoomNotFound
+	}
+
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+
+	var env Envelope
+	switch {
+	case key.controller || key.version < ProtocolV3:
+		snap := room.Snapshot()
+		snap.RemainingMs = game.RemainingMs(snap.Deadline, time.Now())
+		env = Envelope{Type: "room_state", Seq: hist.seq, Payload: snap}
+		if key.controller {
+			env = Envelope{Type: "controller_state", Seq: hist.seq, Payload: controllerState(snap)}
+		}
+	default:
+		if hist.state == nil {
+			hist.nextState(hist.seq, room.Snapshot())
+		}
+		payload := hist.state.payload()
+		payload.RemainingMs = game.RemainingMs(payload.Deadline, time.Now())
+		env = Envelope{Type: "room_state", Seq: hist.seq, Payload: payload}
+	}
+	b, err := encodeForVersion(env, key.version)
+	if err == nil {
+		b, err = key.encode(b)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return b, hist.seq, nil
+}
+
+func (h *Hub) dropHistory(roomCode string) {
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+
+	rc := strings.ToUpper(roomCode)
+	delete(h.history, rc)
+	h.closed[rc] = true
+}
+
+func (h *Hub) reopenHistory(roomCode string) {
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+
+	delete(h.closed, roomCode)
+}
+
+func (h *Hub) CloseRoom(roomCode string, gameOver service.GameOverPayload) {
+	h.bumpRoundGen(roomCode)
+
+	h.Broadcast(roomCode, Envelope{Type: "game_over", Payload: gameOver})
+	h.Broadcast(roomCode, Envelope{Type: "room_closed", Payload: RoomClosedPayload{Code: strings.ToUpper(roomCode)}})
+	h.post(roomMessage{
+		roomCode:  strings.ToUpper(roomCode),
+		closeRoom: websocket.FormatCloseMessage(websocket.CloseNormalClosure, "room closed"),
+	})
+	h.dropHistory(roomCode)
 }
 
 func (h *Hub) run() {
@@ -66,8 +360,12 @@ func (h *Hub) run() {
 			if _, ok := h.clientsByRoom[roomCode]; !ok {
 				h.clientsByRoom[roomCode] = make(map[string]*Client)
 			}
+			if prev, ok := h.clientsByRoom[roomCode][c.playerID]; ok && prev != c {
+				prev.close(nil)
+			}
 			h.clientsByRoom[roomCode][c.playerID] = c
 			h.mu.Unlock()
+			h.reopenHistory(roomCode)
 
 			h.log.Info("ws client registered",
 				zap.String("room", roomCode),
@@ -75,41 +373,101 @@ func (h *Hub) run() {
 			)
 
 		case c := <-h.unregister:
-			h.mu.Lock()
-			roomCode := strings.ToUpper(c.roomCode)
-			if roomClients, ok := h.clientsByRoom[roomCode]; ok {
-				if _, exists := roomClients[c.playerID]; exists {
-					delete(roomClients, c.playerID)
-					close(c.send)
-				}
-				if len(roomClients) == 0 {
-					delete(h.clientsByRoom, roomCode)
-				}
-			}
-			h.mu.Unlock()
+			h.removeClient(c)
+			c.close(nil)
 
 			h.log.Info("ws client unregistered",
-				zap.String("room", roomCode),
+				zap.String("room", strings.ToUpper(c.roomCode)),
 				zap.String("player_id", c.playerID),
 			)
 
 		case msg := <-h.broadcast:
+			if msg.closeRoom != nil {
+				h.closeRoomClients(msg.roomCode, msg.closeRoom)
+				continue
+			}
+
+			var slow []*Client
+			now := time.Now()
 			h.mu.RLock()
 			roomClients := h.clientsByRoom[strings.ToUpper(msg.roomCode)]
-			for _, c := range roomClients {
-				select {
-				case c.send <- msg.data:
-				default:
-					h.mu.RUnlock()
-					h.unregister <- c
-					h.mu.RLock()
+			for id, c := range roomClients {
+				if msg.only != "" && id != msg.only {
+					continue
+				}
+				pf, ok := msg.perPlayer[id]
+				frame := pf.frame
+				if !ok || pf.key != c.frameKey() {
+					var err error
+					if frame, err = msg.frames.get(c.frameKey(), now); err != nil {
+						h.log.Error("ws frame encode failed",
+							zap.String("room", msg.roomCode),
+							zap.String("player_id", id),
+							zap.Error(err),
+						)
+						continue
+					}
+				}
+				if !c.deliver(frame, msg.frames.env.Seq) {
+					slow = append(slow, c)
 				}
 			}
 			h.mu.RUnlock()
+
+			for _, c := range slow {
+				h.dropClient(c)
+			}
 		}
 	}
 }
 
+func (h *Hub) removeClient(c *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	roomCode := strings.ToUpper(c.roomCode)
+	roomClients, ok := h.clientsByRoom[roomCode]
+	if !ok {
+		return false
+	}
+	current, removed := roomClients[c.playerID]
+	removed = removed && current == c
+	if removed {
+		delete(roomClients, c.playerID)
+	}
+	if len(roomClients) == 0 {
+		delete(h.clientsByRoom, roomCode)
+	}
+	return removed
+}
+
+func (h *Hub) dropClient(c *Client) {
+	if h.removeClient(c) {
+		h.log.Warn("ws client dropped: send buffer full",
+			zap.String("room", strings.ToUpper(c.roomCode)),
+			zap.String("player_id", c.playerID),
+		)
+	}
+	c.close(nil)
+}
+
+func (h *Hub) closeRoomClients(roomCode string, closeMsg []byte) {
+	h.mu.Lock()
+	rc := strings.ToUpper(roomCode)
+	roomClients := h.clientsByRoom[rc]
+	delete(h.clientsByRoom, rc)
+	h.mu.Unlock()
+
+	for _, c := range roomClients {
+		c.close(closeMsg)
+	}
+
+	h.log.Info("ws room closed",
+		zap.String("room", rc),
+		zap.Int("clients", len(roomClients)),
+	)
+}
+
 func (h *Hub) bumpRoundGen(roomCode string) int64 {
 	h.roundGenMu.Lock()
 	defer h.roundGenMu.Unlock()
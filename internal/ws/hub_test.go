@@ -0,0 +1,241 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func decodeSeqs(t *testing.T, events [][]byte) []uint64 {
+	t.Helper()
+
+	seqs := make([]uint64, 0, len(events))
+	for _, e := range events {
+		var env struct {
+			Seq uint64 `json:"seq"`
+		}
+		require.NoError(t, json.Unmarshal(e, &env))
+		seqs = append(seqs, env.Seq)
+	}
+	return seqs
+}
+
+func TestHub_Broadcast_AssignsPerRoomSeq(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+
+	h.Broadcast("abcd", Envelope{Type: "room_state"})
+	h.Broadcast("ABCD", Envelope{Type: "room_state"})
+	h.Broadcast("WXYZ", Envelope{Type: "room_state"})
+
+	events, seq, ok := h.replaySince("ABCD", "", 0, frameKey{version: CurrentProtocolVersion})
+	require.True(t, ok)
+	require.Equal(t, uint64(2), seq)
+	require.Equal(t, []uint64{1, 2}, decodeSeqs(t, events))
+
+	events, seq, ok = h.replaySince("WXYZ", "", 0, frameKey{version: CurrentProtocolVersion})
+	require.True(t, ok)
+	require.Equal(t, uint64(1), seq)
+	require.Equal(t, []uint64{1}, decodeSeqs(t, events))
+}
+
+func TestHub_ReplaySince_ReturnsMissedEvents(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	for i := 0; i < 5; i++ {
+		h.Broadcast("ABCD", Envelope{Type: "room_state"})
+	}
+
+	events, _, ok := h.replaySince("ABCD", "", 3, frameKey{version: CurrentProtocolVersion})
+	require.True(t, ok)
+	require.Equal(t, []uint64{4, 5}, decodeSeqs(t, events))
+
+	events, _, ok = h.replaySince("ABCD", "", 5, frameKey{version: CurrentProtocolVersion})
+	require.True(t, ok)
+	require.Empty(t, events)
+}
+
+func TestHub_ReplaySince_GapTooLarge(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	for i := 0; i < eventHistorySize+10; i++ {
+		h.Broadcast("ABCD", Envelope{Type: "room_state"})
+	}
+
+	_, seq, ok := h.replaySince("ABCD", "", 5, frameKey{version: CurrentProtocolVersion})
+	require.False(t, ok)
+	require.Equal(t, uint64(eventHistorySize+10), seq)
+
+	events, _, ok := h.replaySince("ABCD", "", 10, frameKey{version: CurrentProtocolVersion})
+	require.True(t, ok)
+	require.Len(t, events, eventHistorySize)
+
+	_, _, ok = h.replaySince("ABCD", "", 1000, frameKey{version: CurrentProtocolVersion})
+	require.False(t, ok)
+}
+
+func registerTestClient(t *testing.T, h *Hub, roomCode, playerID string, version int) *Client {
+	t.Helper()
+
+	c := &Client{hub: h, roomCode: roomCode, playerID: playerID, version: version, send: make(chan []byte, 16), done: make(chan struct{})}
+	h.register <- c
+	require.Eventually(t, func() bool {
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		return h.clientsByRoom[roomCode][playerID] == c
+	}, time.Second, time.Millisecond)
+	return c
+}
+
+func receiveType(t *testing.T, c *Client) map[string]interface{} {
+	t.Helper()
+
+	select {
+	case b := <-c.send:
+		var env map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &env))
+		return env
+	case <-time.After(time.Second):
+		t.Fatalf("no message for %s", c.playerID)
+		return nil
+	}
+}
+
+func TestHub_SendTo_OnlyTargetPlayer(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	alice := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+	bob := registerTestClient(t, h, "ABCD", "bob", ProtocolV2)
+
+	require.True(t, h.SendTo("abcd", "alice", "secret_role", map[string]string{"role": "spy"}))
+	require.False(t, h.SendTo("ABCD", "ghost", "secret_role", nil))
+	h.Broadcast("ABCD", Envelope{Type: "room_state"})
+
+	env := receiveType(t, alice)
+	require.Equal(t, "secret_role", env["type"])
+	require.NotContains(t, env, "seq")
+	require.Equal(t, "room_state", receiveType(t, alice)["type"])
+
+	require.Equal(t, "room_state", receiveType(t, bob)["type"])
+}
+
+func TestHub_BroadcastEach_PersonalVariants(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	alice := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+	bob := registerTestClient(t, h, "ABCD", "bob", ProtocolV2)
+	legacy := registerTestClient(t, h, "ABCD", "legacy", ProtocolV1)
+
+	h.BroadcastEach("ABCD", "hint", map[string]string{"text": "common"}, func(playerID string) interface{} {
+		if playerID == "bob" {
+			return nil
+		}
+		return map[string]string{"text": "for " + playerID}
+	})
+
+	env := receiveType(t, alice)
+	require.Equal(t, float64(1), env["seq"])
+	require.Equal(t, "for alice", env["payload"].(map[string]interface{})["text"])
+	require.Equal(t, "common", receiveType(t, bob)["payload"].(map[string]interface{})["text"])
+	require.Equal(t, "common", receiveType(t, legacy)["payload"].(map[string]interface{})["text"])
+
+	events, _, ok := h.replaySince("ABCD", "", 0, frameKey{version: ProtocolV2})
+	require.True(t, ok)
+	require.Contains(t, string(events[0]), "common")
+
+	events, _, ok = h.replaySince("ABCD", "alice", 0, alice.frameKey())
+	require.True(t, ok)
+	require.Contains(t, string(events[0]), "for alice")
+
+	events, _, ok = h.replaySince("ABCD", "alice", 0, frameKey{version: ProtocolV2, binary: true})
+	require.True(t, ok)
+	require.NotContains(t, string(events[0]), "for alice")
+}
+
+func TestHub_CloseRoom_LateSendDoesNotPanic(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	c := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+
+	h.CloseRoom("ABCD", game.GameOverPayload{Code: "ABCD"})
+
+	select {
+	case <-c.done:
+	case <-time.After(time.Second):
+		t.Fatal("client not closed")
+	}
+	require.NotNil(t, c.closeMsg)
+
+	require.NotPanics(t, func() {
+		c.sendJSON(errorEnvelope("late"))
+	})
+	require.Equal(t, "game_over", receiveType(t, c)["type"])
+	require.Equal(t, "room_closed", receiveType(t, c)["type"])
+}
+
+func TestHub_CloseRoom_LateBroadcastKeepsHistoryDropped(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	c := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+	h.Broadcast("ABCD", Envelope{Type: "room_state"})
+
+	h.CloseRoom("abcd", game.GameOverPayload{Code: "ABCD"})
+	<-c.done
+
+	h.Broadcast("ABCD", Envelope{Type: "room_state", Payload: roomWithPlayers(1).Snapshot()})
+	h.broadcastEphemeral("ABCD", Envelope{Type: "reactions"})
+	_, err := h.fullState("ABCD", roomWithPlayers(1), frameKey{version: ProtocolV3})
+	require.ErrorIs(t, err, game.ErrRoomNotFound)
+
+	h.historyMu.Lock()
+	require.Empty(t, h.history)
+	h.historyMu.Unlock()
+
+	registerTestClient(t, h, "ABCD", "bob", ProtocolV2)
+	h.Broadcast("ABCD", Envelope{Type: "room_state"})
+	_, seq, ok := h.replaySince("ABCD", "bob", 0, frameKey{version: ProtocolV2})
+	require.True(t, ok)
+	require.Equal(t, uint64(1), seq)
+}
+
+func TestHub_Register_ResumeClosesPreviousClient(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	prev := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+	next := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+
+	select {
+	case <-prev.done:
+	case <-time.After(time.Second):
+		t.Fatal("previous client not closed")
+	}
+	require.NotPanics(t, func() {
+		prev.sendJSON(errorEnvelope("late"))
+	})
+
+	h.Broadcast("ABCD", Envelope{Type: "room_state"})
+	require.Equal(t, "room_state", receiveType(t, next)["type"])
+}
+
+func TestHub_BroadcastEach_ConcurrentKeepsRoomOrder(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	c := &Client{hub: h, roomCode: "ABCD", playerID: "alice", version: ProtocolV2, send: make(chan []byte, 256), done: make(chan struct{})}
+	h.register <- c
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				h.Broadcast("ABCD", Envelope{Type: "answer_accepted"})
+				h.Broadcast("WXYZ", Envelope{Type: "answer_accepted"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	var last float64
+	for i := 0; i < 100; i++ {
+		seq := receiveType(t, c)["seq"].(float64)
+		require.Greater(t, seq, last)
+		last = seq
+	}
+}
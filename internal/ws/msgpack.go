@@ -0,0 +1,112 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	SubprotocolJSON    = "json"
+	SubprotocolMsgpack = "msgpack"
+)
+
+var subprotocols = []string{SubprotocolMsgpack, SubprotocolJSON}
+
+type frameKey struct {
+	version    int
+	binary     bool
+	controller bool
+}
+
+func (k frameKey) encode(jsonFrame []byte) ([]byte, error) {
+	if !k.binary {
+		return jsonFrame, nil
+	}
+	return jsonToMsgpack(jsonFrame)
+}
+
+func (k frameKey) messageType() int {
+	if k.binary {
+		return websocket.BinaryMessage
+	}
+	return websocket.TextMessage
+}
+
+func readClientMsg(conn *websocket.Conn) (clientMsg, error) {
+	var msg clientMsg
+	mt, data, err := conn.ReadMessage()
+	if err != nil {
+		return msg, err
+	}
+	if mt == websocket.BinaryMessage {
+		if data, err = msgpackToJSON(data); err != nil {
+			return msg, err
+		}
+	}
+	err = json.Unmarshal(data, &msg)
+	return msg, err
+}
+
+func writeEnvelope(conn *websocket.Conn, binary bool, env Envelope) error {
+	key := frameKey{binary: binary}
+	b, err := json.Marshal(env)
+	if err == nil {
+		b, err = key.encode(b)
+	}
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(key.messageType(), b)
+}
+
+func jsonToMsgpack(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(normalizeNumbers(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func msgpackToJSON(b []byte) ([]byte, error) {
+	var v interface{}
+	if err := msgpack.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func normalizeNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = normalizeNumbers(e)
+		}
+		return t
+	case []interface{}:
+		for i, e := range t {
+			t[i] = normalizeNumbers(e)
+		}
+		return t
+	default:
+		return v
+	}
+}
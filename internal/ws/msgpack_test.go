@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.uber.org/zap"
+)
+
+func TestJSONToMsgpack_RoundTrip(t *testing.T) {
+	in := []byte(`{"type":"room_state","seq":42,"payload":{"deadline":1700000000123,"ratio":0.5,"players":[{"id":"p1","ready":true}],"question":null}}`)
+
+	bin, err := jsonToMsgpack(in)
+	require.NoError(t, err)
+	require.Less(t, len(bin), len(in))
+
+	var decoded map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(bin, &decoded))
+	require.Equal(t, "room_state", decoded["type"])
+	require.EqualValues(t, 42, decoded["seq"])
+	require.EqualValues(t, int64(1700000000123), decoded["payload"].(map[string]interface{})["deadline"])
+
+	out, err := msgpackToJSON(bin)
+	require.NoError(t, err)
+	require.JSONEq(t, string(in), string(out))
+}
+
+func TestEncodeFrames_BinaryVariantEncodedOnFirstGet(t *testing.T) {
+	f := newFrames(Envelope{Type: "answer_accepted", Seq: 3, Payload: AnswerAcceptedPayload{OK: true}})
+	require.Empty(t, f.enc)
+
+	for v := MinProtocolVersion; v <= CurrentProtocolVersion; v++ {
+		text, err := f.get(frameKey{version: v}, time.Now())
+		require.NoError(t, err)
+		bin, err := f.get(frameKey{version: v, binary: true}, time.Now())
+		require.NoError(t, err)
+		require.NotEmpty(t, text)
+		require.NotEmpty(t, bin)
+		require.Contains(t, f.enc, frameKey{version: v, binary: true})
+
+		out, err := msgpackToJSON(bin)
+		require.NoError(t, err)
+		require.JSONEq(t, string(text), string(out))
+	}
+}
+
+func TestHub_Broadcast_MsgpackClient(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	text := registerTestClient(t, h, "ABCD", "text", ProtocolV2)
+	bin := registerTestClient(t, h, "ABCD", "bin", ProtocolV2)
+	bin.binary = true
+
+	h.Broadcast("ABCD", Envelope{Type: "answer_accepted", Payload: AnswerAcceptedPayload{OK: true}})
+
+	require.Equal(t, "answer_accepted", receiveType(t, text)["type"])
+
+	var env map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(<-bin.send, &env))
+	require.Equal(t, "answer_accepted", env["type"])
+
+	events, _, ok := h.replaySince("ABCD", "", 0, bin.frameKey())
+	require.True(t, ok)
+	var replayed map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(events[0], &replayed))
+	require.EqualValues(t, 1, replayed["seq"])
+	require.False(t, json.Valid(events[0]))
+}
@@ -0,0 +1,64 @@
+package ws
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+func newUpgrader(cfg Config, h *Hub) websocket.Upgrader {
+	return websocket.Upgrader{
+		HandshakeTimeout:  cfg.HandshakeTimeout,
+		ReadBufferSize:    cfg.ReadBufferSize,
+		WriteBufferSize:   cfg.WriteBufferSize,
+		EnableCompression: cfg.EnableCompression,
+		Subprotocols:      subprotocols,
+		CheckOrigin:       h.checkOrigin,
+	}
+}
+
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || originAllowed(h.cfg.AllowedOrigins, origin, r.Host) {
+		return true
+	}
+
+	h.counters.originRejected.Add(1)
+	h.log.Warn("ws origin rejected",
+		zap.String("origin", origin),
+		zap.String("host", r.Host),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+	return false
+}
+
+func originAllowed(patterns []string, origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if len(patterns) == 0 {
+		return strings.EqualFold(u.Host, host)
+	}
+
+	origin = strings.ToLower(u.Scheme + "://" + u.Host)
+	hostname := strings.ToLower(u.Host)
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(p), "/"))
+		if p == "*" {
+			return true
+		}
+		target := hostname
+		if strings.Contains(p, "://") {
+			target = origin
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/ArtemMoroz51/FinalProject/internal/service"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	patterns := []string{"https://*.example.com", "https://quiz.tv/", "localhost:*"}
+
+	cases := []struct {
+		origin string
+		ok     bool
+	}{
+		{"https://play.example.com", true},
+		{"https://PLAY.Example.com", true},
+		{"http://play.example.com", false},
+		{"https://example.com", false},
+		{"https://example.com.evil.io", false},
+		{"https://quiz.tv", true},
+		{"http://localhost:5173", true},
+		{"https://evil.io", false},
+		{"null", false},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.ok, originAllowed(patterns, tc.origin, "api.example.com"), tc.origin)
+	}
+
+	require.True(t, originAllowed([]string{"*"}, "https://anything.io", "api.example.com"))
+}
+
+func TestOriginAllowed_SameOriginByDefault(t *testing.T) {
+	require.True(t, originAllowed(nil, "https://quiz.example.com", "quiz.example.com"))
+	require.False(t, originAllowed(nil, "https://evil.io", "quiz.example.com"))
+}
+
+func TestHub_CheckOrigin_CountsRejected(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{AllowedOrigins: []string{"https://*.example.com"}})
+
+	r := httptest.NewRequest("GET", "/ws/ABCD", nil)
+	require.True(t, h.checkOrigin(r))
+
+	r.Header.Set("Origin", "https://evil.io")
+	require.False(t, h.checkOrigin(r))
+	require.Equal(t, uint64(1), h.Stats().OriginRejected)
+}
+
+func TestConfig_WithDefaults(t *testing.T) {
+	cfg := Config{PongWait: 20 * time.Second, RateLimits: RateLimits{Connection: Limit{Burst: 5, PerSecond: 1}}}.withDefaults()
+
+	require.Equal(t, int64(maxMessageSize), cfg.MaxMessageSize)
+	require.Less(t, cfg.PingPeriod, cfg.PongWait)
+	require.Equal(t, Limit{Burst: 5, PerSecond: 1}, cfg.RateLimits.Connection)
+	require.NotEmpty(t, cfg.RateLimits.PerType)
+}
+
+func TestServeWS_ReadLimitAppliesToJoin(t *testing.T) {
+	svc := service.NewGameService(game.NewRoomManager(), nil, service.Config{})
+	room := svc.CreateRoom()
+	h := NewHub(svc, zap.NewNop(), Config{MaxMessageSize: 256})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeWS(w, r, room.Code)
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	padding := strings.Repeat(" ", 4096)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"join_room",`+padding+`"payload":{"name":"Alex"}}`)))
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	require.Empty(t, room.Snapshot().Players)
+}
@@ -0,0 +1,201 @@
+package ws
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+)
+
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+func (o PatchOp) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type plain PatchOp
+	return json.Marshal(plain(o))
+}
+
+type RoomPatchPayload struct {
+	StateVersion uint64    `json:"stateVersion"`
+	BaseVersion  uint64    `json:"baseVersion"`
+	Ops          []PatchOp `json:"ops"`
+	RemainingMs  int64     `json:"remainingMs,omitempty"`
+}
+
+type VersionedRoomState struct {
+	StateVersion uint64 `json:"stateVersion,omitempty"`
+	game.RoomSnapshot
+}
+
+type roomState struct {
+	version uint64
+	snap    game.RoomSnapshot
+}
+
+func (h *roomHistory) nextState(seq uint64, snap game.RoomSnapshot) Envelope {
+	prev := h.state
+	h.state = &roomState{version: h.stateVersion() + 1, snap: snap}
+
+	if prev == nil {
+		return Envelope{Type: "room_state", Seq: seq, Payload: h.state.payload()}
+	}
+	return Envelope{Type: "room_patch", Seq: seq, Payload: RoomPatchPayload{
+		StateVersion: h.state.version,
+		BaseVersion:  prev.version,
+		Ops:          diffSnapshots(prev.snap, snap),
+	}}
+}
+
+func (h *roomHistory) stateVersion() uint64 {
+	if h.state == nil {
+		return 0
+	}
+	return h.state.version
+}
+
+func (s *roomState) payload() VersionedRoomState {
+	return VersionedRoomState{StateVersion: s.version, RoomSnapshot: s.snap}
+}
+
+type snapshotDiff struct {
+	ops []PatchOp
+}
+
+func diffSnapshots(a, b game.RoomSnapshot) []PatchOp {
+	d := &snapshotDiff{ops: []PatchOp{}}
+
+	d.field("/code", a.Code != b.Code, true, true, b.Code)
+	d.field("/phase", a.Phase != b.Phase, true, true, b.Phase)
+	d.field("/hostId", a.HostID != b.HostID, true, true, b.HostID)
+	d.field("/roundNumber", a.RoundNumber != b.RoundNumber, true, true, b.RoundNumber)
+	d.field("/settings", a.Settings != b.Settings, true, true, b.Settings)
+	d.field("/question", a.Question != b.Question, a.Question != "", b.Question != "", b.Question)
+	d.field("/options", !optionsEqual(a.Options, b.Options), len(a.Options) > 0, len(b.Options) > 0, b.Options)
+	d.field("/deadline", a.Deadline != b.Deadline, a.Deadline != 0, b.Deadline != 0, b.Deadline)
+	d.field("/remainingMs", a.RemainingMs != b.RemainingMs, a.RemainingMs != 0, b.RemainingMs != 0, b.RemainingMs)
+	d.players(a.Players, b.Players)
+	d.scores(a.Scores, b.Scores)
+	d.answered(a.Answered, b.Answered)
+	d.field("/paused", a.Paused != b.Paused, true, true, b.Paused)
+	d.field("/pausedRemainingMs", a.PausedRemainingMs != b.PausedRemainingMs, a.PausedRemainingMs != 0, b.PausedRemainingMs != 0, b.PausedRemainingMs)
+	d.field("/displays", a.Displays != b.Displays, true, true, b.Displays)
+	d.field("/spectators", a.Spectators != b.Spectators, true, true, b.Spectators)
+	d.field("/previousGame", a.PreviousGame != b.PreviousGame, a.PreviousGame != nil, b.PreviousGame != nil, b.PreviousGame)
+
+	return d.ops
+}
+
+func (d *snapshotDiff) field(path string, changed, inA, inB bool, value interface{}) {
+	switch {
+	case inA && !inB:
+		d.ops = append(d.ops, PatchOp{Op: "remove", Path: path})
+	case !inA && inB:
+		d.ops = append(d.ops, PatchOp{Op: "add", Path: path, Value: value})
+	case inA && inB && changed:
+		d.ops = append(d.ops, PatchOp{Op: "replace", Path: path, Value: value})
+	}
+}
+
+func (d *snapshotDiff) players(a, b []*game.Player) {
+	if (a == nil) != (b == nil) {
+		d.field("/players", true, true, true, b)
+		return
+	}
+
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		pa, pb := a[i], b[i]
+		if *pa == *pb {
+			continue
+		}
+		path := "/players/" + strconv.Itoa(i)
+		d.field(path+"/id", pa.ID != pb.ID, true, true, pb.ID)
+		d.field(path+"/name", pa.Name != pb.Name, true, true, pb.Name)
+		d.field(path+"/joinOrder", pa.JoinOrder != pb.JoinOrder, true, true, pb.JoinOrder)
+		d.field(path+"/ready", pa.Ready != pb.Ready, true, true, pb.Ready)
+		d.field(path+"/online", pa.Online != pb.Online, true, true, pb.Online)
+		d.field(path+"/avatar", pa.Avatar != pb.Avatar, true, true, pb.Avatar)
+		d.field(path+"/color", pa.Color != pb.Color, true, true, pb.Color)
+		d.field(path+"/muted", pa.Muted != pb.Muted, pa.Muted, pb.Muted, pb.Muted)
+	}
+	for i := n; i < len(b); i++ {
+		d.ops = append(d.ops, PatchOp{Op: "add", Path: "/players/" + strconv.Itoa(i), Value: b[i]})
+	}
+	for i := len(a) - 1; i >= n; i-- {
+		d.ops = append(d.ops, PatchOp{Op: "remove", Path: "/players/" + strconv.Itoa(i)})
+	}
+}
+
+func (d *snapshotDiff) scores(a, b map[string]int) {
+	if (a == nil) != (b == nil) {
+		d.field("/scores", true, true, true, b)
+		return
+	}
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		va, inA := a[k]
+		vb, inB := b[k]
+		d.field("/scores/"+escapePointer(k), va != vb, inA, inB, vb)
+	}
+}
+
+func (d *snapshotDiff) answered(a, b map[string]bool) {
+	if len(a) == 0 || len(b) == 0 {
+		d.field("/answered", true, len(a) > 0, len(b) > 0, b)
+		return
+	}
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		va, inA := a[k]
+		vb, inB := b[k]
+		d.field("/answered/"+escapePointer(k), va != vb, inA, inB, vb)
+	}
+}
+
+func optionsEqual(a, b []game.Option) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,337 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func applyPatch(t *testing.T, doc interface{}, ops []PatchOp) interface{} {
+	t.Helper()
+
+	for _, op := range ops {
+		doc = applyOp(t, doc, strings.Split(op.Path, "/")[1:], op)
+	}
+	return doc
+}
+
+func applyOp(t *testing.T, node interface{}, path []string, op PatchOp) interface{} {
+	t.Helper()
+
+	if len(path) == 0 {
+		return op.Value
+	}
+	key := strings.ReplaceAll(strings.ReplaceAll(path[0], "~1", "/"), "~0", "~")
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			if op.Op == "remove" {
+				delete(n, key)
+			} else {
+				n[key] = op.Value
+			}
+			return n
+		}
+		n[key] = applyOp(t, n[key], path[1:], op)
+		return n
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		require.NoError(t, err)
+		if len(path) == 1 {
+			switch op.Op {
+			case "remove":
+				return append(n[:i], n[i+1:]...)
+			case "add":
+				return append(n[:i], append([]interface{}{op.Value}, n[i:]...)...)
+			default:
+				n[i] = op.Value
+				return n
+			}
+		}
+		n[i] = applyOp(t, n[i], path[1:], op)
+		return n
+	default:
+		t.Fatalf("bad patch path %q", op.Path)
+		return nil
+	}
+}
+
+func roomWithPlayers(n int) *game.Room {
+	room := game.NewRoomManager().CreateRoom()
+	room.Code = "ABCD"
+	for i := 0; i < n; i++ {
+		room.AddPlayer(&game.Player{ID: fmt.Sprintf("p%d", i), Name: fmt.Sprintf("Player %d", i)})
+	}
+	return room
+}
+
+func requirePatchRoundTrip(t *testing.T, before, after game.RoomSnapshot) []PatchOp {
+	t.Helper()
+
+	rawA, err := json.Marshal(before)
+	require.NoError(t, err)
+	rawB, err := json.Marshal(after)
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(diffSnapshots(before, after))
+	require.NoError(t, err)
+	var decoded []PatchOp
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+
+	var a, b interface{}
+	require.NoError(t, json.Unmarshal(rawA, &a))
+	require.NoError(t, json.Unmarshal(rawB, &b))
+	require.Equal(t, b, applyPatch(t, a, decoded))
+	return decoded
+}
+
+func TestDiffSnapshots_PlayerJoinAndReady(t *testing.T) {
+	room := roomWithPlayers(3)
+	before := room.Snapshot()
+
+	room.AddPlayer(&game.Player{ID: "p9", Name: "Late"})
+	_, _, err := room.SetReady("p1", true)
+	require.NoError(t, err)
+
+	ops := requirePatchRoundTrip(t, before, room.Snapshot())
+	require.Contains(t, ops, PatchOp{Op: "replace", Path: "/players/1/ready", Value: true})
+}
+
+func TestDiffSnapshots_PlayerLeaves(t *testing.T) {
+	room := roomWithPlayers(4)
+	before := room.Snapshot()
+
+	room.RemovePlayer("p0")
+
+	requirePatchRoundTrip(t, before, room.Snapshot())
+}
+
+func TestDiffSnapshots_NoChanges(t *testing.T) {
+	room := roomWithPlayers(2)
+
+	ops := requirePatchRoundTrip(t, room.Snapshot(), room.Snapshot())
+	require.Empty(t, ops)
+}
+
+func TestDiffSnapshots_RoundLifecycle(t *testing.T) {
+	room := roomWithPlayers(3)
+	lobby := room.Snapshot()
+
+	require.NoError(t, room.StartGame("p0", game.Question{
+		Text:      "2+2?",
+		Options:   []game.Option{{ID: "a", Text: "4"}, {ID: "b", Text: "5"}, {ID: "c", Text: "6"}, {ID: "d", Text: "7"}},
+		CorrectID: "a",
+	}, 30))
+	answering := room.Snapshot()
+	ops := requirePatchRoundTrip(t, lobby, answering)
+	require.Contains(t, ops, PatchOp{Op: "replace", Path: "/phase", Value: string(game.PhaseAnswering)})
+
+	require.NoError(t, room.SubmitAnswer("p1", "a"))
+	answered := room.Snapshot()
+	requirePatchRoundTrip(t, answering, answered)
+
+	require.NoError(t, room.Pause("p0"))
+	paused := room.Snapshot()
+	requirePatchRoundTrip(t, answered, paused)
+
+	require.NoError(t, room.Resume("p0"))
+	require.NoError(t, room.SubmitAnswer("p0", "b"))
+	require.NoError(t, room.SubmitAnswer("p2", "a"))
+	_, ok := room.FinishRoundIfAllAnswered()
+	require.True(t, ok)
+	results := room.Snapshot()
+	requirePatchRoundTrip(t, paused, results)
+
+	require.NoError(t, room.ResetGame("p0"))
+	requirePatchRoundTrip(t, results, room.Snapshot())
+	requirePatchRoundTrip(t, room.Snapshot(), lobby)
+}
+
+func TestPatchOp_RemoveHasNoValue(t *testing.T) {
+	b, err := json.Marshal(PatchOp{Op: "remove", Path: "/deadline"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"op":"remove","path":"/deadline"}`, string(b))
+
+	b, err = json.Marshal(PatchOp{Op: "replace", Path: "/question", Value: nil})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"op":"replace","path":"/question","value":null}`, string(b))
+}
+
+func TestHub_Broadcast_RoomPatchForV3(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	room := roomWithPlayers(2)
+
+	h.Broadcast("ABCD", Envelope{Type: "room_state", Payload: room.Snapshot()})
+	room.AddPlayer(&game.Player{ID: "p5", Name: "New"})
+	h.Broadcast("ABCD", Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+	events, _, ok := h.replaySince("ABCD", "", 0, frameKey{version: ProtocolV3})
+	require.True(t, ok)
+	require.Len(t, events, 2)
+
+	var full struct {
+		Type    string             `json:"type"`
+		Payload VersionedRoomState `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(events[0], &full))
+	require.Equal(t, "room_state", full.Type)
+	require.Equal(t, uint64(1), full.Payload.StateVersion)
+	require.Len(t, full.Payload.Players, 2)
+
+	var patch struct {
+		Type    string           `json:"type"`
+		Seq     uint64           `json:"seq"`
+		Payload RoomPatchPayload `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(events[1], &patch))
+	require.Equal(t, "room_patch", patch.Type)
+	require.Equal(t, uint64(2), patch.Seq)
+	require.Equal(t, uint64(1), patch.Payload.BaseVersion)
+	require.Equal(t, uint64(2), patch.Payload.StateVersion)
+	require.NotEmpty(t, patch.Payload.Ops)
+
+	events, _, ok = h.replaySince("ABCD", "", 0, frameKey{version: ProtocolV2})
+	require.True(t, ok)
+	for _, e := range events {
+		var env struct {
+			Type string `json:"type"`
+		}
+		require.NoError(t, json.Unmarshal(e, &env))
+		require.Equal(t, "room_state", env.Type)
+	}
+}
+
+func TestHub_FullState_ReturnsCurrentVersion(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	room := roomWithPlayers(2)
+
+	b, err := h.fullState("ABCD", room, frameKey{version: ProtocolV3})
+	require.NoError(t, err)
+	var first struct {
+		Payload VersionedRoomState `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(b, &first))
+	require.Equal(t, uint64(1), first.Payload.StateVersion)
+
+	room.AddPlayer(&game.Player{ID: "p5", Name: "New"})
+	h.Broadcast("ABCD", Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+	b, err = h.fullState("abcd", room, frameKey{version: ProtocolV3})
+	require.NoError(t, err)
+	var second struct {
+		Seq     uint64             `json:"seq"`
+		Payload VersionedRoomState `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(b, &second))
+	require.Equal(t, uint64(2), second.Payload.StateVersion)
+	require.Equal(t, uint64(1), second.Seq)
+	require.Len(t, second.Payload.Players, 3)
+}
+
+const benchPlayers = 100
+
+func benchmarkRoom(b *testing.B) *game.Room {
+	b.Helper()
+	room := game.NewRoomManager().CreateRoom()
+	for i := 0; i < benchPlayers; i++ {
+		room.AddPlayer(&game.Player{ID: fmt.Sprintf("player-%03d", i), Name: fmt.Sprintf("Player %d", i)})
+	}
+	return room
+}
+
+func toggleReady(room *game.Room, i int) {
+	_, _, _ = room.SetReady(fmt.Sprintf("player-%03d", i%benchPlayers), i%(2*benchPlayers) < benchPlayers)
+}
+
+func BenchmarkRoomState_FullSnapshot(b *testing.B) {
+	room := benchmarkRoom(b)
+	var bytes int
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		toggleReady(room, i)
+		frame, err := encodeForVersion(Envelope{Type: "room_state", Seq: uint64(i + 1), Payload: room.Snapshot()}, ProtocolV2)
+		if err != nil {
+			b.Fatal(err)
+		}
+		bytes += len(frame)
+	}
+	b.ReportMetric(float64(bytes)/float64(b.N), "bytes/msg")
+}
+
+func BenchmarkRoomState_Patch(b *testing.B) {
+	room := benchmarkRoom(b)
+	hist := &roomHistory{}
+	hist.nextState(0, room.Snapshot())
+	var bytes int
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		toggleReady(room, i)
+		frame, err := encodeForVersion(hist.nextState(uint64(i+1), room.Snapshot()), ProtocolV3)
+		if err != nil {
+			b.Fatal(err)
+		}
+		bytes += len(frame)
+	}
+	b.ReportMetric(float64(bytes)/float64(b.N), "bytes/msg")
+}
+
+func BenchmarkHub_BroadcastRoomState(b *testing.B) {
+	room := benchmarkRoom(b)
+	h := NewHub(nil, zap.NewNop(), Config{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		toggleReady(room, i)
+		h.Broadcast(room.Code, Envelope{Type: "room_state", Payload: room.Snapshot()})
+	}
+}
+
+func BenchmarkFrames_RoomStateAgainstFullSnapshot(b *testing.B) {
+	cases := []struct {
+		name  string
+		key   frameKey
+		patch bool
+	}{
+		{name: "full_snapshot_v2", key: frameKey{version: ProtocolV2}},
+		{name: "patch_v3", key: frameKey{version: ProtocolV3}, patch: true},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			room := benchmarkRoom(b)
+			hist := &roomHistory{}
+			hist.nextState(0, room.Snapshot())
+			now := time.Now()
+			var bytes int
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				toggleReady(room, i)
+				env := Envelope{Type: "room_state", Seq: uint64(i + 1), Payload: room.Snapshot()}
+				f := newFrames(env)
+				if tc.patch {
+					f.setState(hist.nextState(env.Seq, env.Payload.(game.RoomSnapshot)))
+				}
+				frame, err := f.get(tc.key, now)
+				if err != nil {
+					b.Fatal(err)
+				}
+				bytes += len(frame)
+			}
+			b.ReportMetric(float64(bytes)/float64(b.N), "bytes/msg")
+		})
+	}
+}
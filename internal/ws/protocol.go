@@ -1,20 +1,122 @@
 package ws
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+)
 
 type Envelope struct {
 	Type    string      `json:"type"`
+	Seq     uint64      `json:"seq,omitempty"`
 	Payload interface{} `json:"payload"`
 }
 
+type Handshake struct {
+	ProtocolVersion int      `json:"protocolVersion,omitempty"`
+	Capabilities    []string `json:"capabilities,omitempty"`
+	Role            string   `json:"role,omitempty"`
+}
+
 type JoinPayload struct {
-	Name string `json:"name"`
+	Handshake
+	Name      string `json:"name"`
+	Avatar    string `json:"avatar,omitempty"`
+	Color     string `json:"color,omitempty"`
+	HostToken string `json:"hostToken,omitempty"`
+}
+
+type ResumePayload struct {
+	Handshake
+	Token string `json:"token"`
+}
+
+type JoinedPayload struct {
+	PlayerID    string `json:"playerId"`
+	ResumeToken string `json:"resumeToken"`
+	Answer      string `json:"answer,omitempty"`
+	Role        string `json:"role,omitempty"`
 }
 
 type SubmitAnswerPayload struct {
 	OptionID string `json:"optionId"`
 }
 
+type SetReadyPayload struct {
+	Ready bool `json:"ready"`
+}
+
+type UpdateSettingsPayload struct {
+	EndRoundEarly       *bool `json:"endRoundEarly,omitempty"`
+	HostPaced           *bool `json:"hostPaced,omitempty"`
+	ChatDuringAnswering *bool `json:"chatDuringAnswering,omitempty"`
+	RequireDisplay      *bool `json:"requireDisplay,omitempty"`
+}
+
+type ChatSendPayload struct {
+	Text string `json:"text"`
+}
+
+type ChatHistoryPayload struct {
+	Messages []game.ChatMessage `json:"messages"`
+}
+
+type MutePlayerPayload struct {
+	PlayerID string `json:"playerId"`
+	Muted    bool   `json:"muted"`
+}
+
+type SyncPayload struct {
+	LastSeq uint64 `json:"lastSeq"`
+}
+
+type TimeSyncPayload struct {
+	ClientTime int64 `json:"clientTime"`
+}
+
+type TimeSyncReplyPayload struct {
+	ClientTime    int64 `json:"clientTime"`
+	ServerReceive int64 `json:"serverReceive"`
+	ServerSend    int64 `json:"serverSend"`
+}
+
+type TransferHostPayload struct {
+	PlayerID string `json:"playerId"`
+}
+
+type HostChangedPayload struct {
+	OldHostID string `json:"oldHostId"`
+	NewHostID string `json:"newHostId"`
+}
+
+type PersonalRoundResults struct {
+	*game.RoundResultsPayload
+	YourAnswer string `json:"yourAnswer,omitempty"`
+	YourRank   int    `json:"yourRank,omitempty"`
+}
+
+type EmptyPayload struct{}
+
+type AnswerAcceptedPayload struct {
+	OK bool `json:"ok"`
+}
+
+type RoomClosedPayload struct {
+	Code string `json:"code"`
+}
+
+type ErrorPayload struct {
+	Message    string `json:"message"`
+	Code       string `json:"code,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func errorEnvelope(message string) Envelope {
+	return Envelope{Type: "error", Payload: ErrorPayload{Message: message}}
+}
+
 type clientMsg struct {
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
@@ -0,0 +1,121 @@
+package ws
+
+import "time"
+
+type tokenBucket struct {
+	capacity float64
+	rate     float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(capacity int, perSecond float64) *tokenBucket {
+	return &tokenBucket{capacity: float64(capacity), rate: perSecond, tokens: float64(capacity)}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type Limit struct {
+	Burst     int
+	PerSecond float64
+}
+
+type RateLimits struct {
+	Connection Limit
+	PerType    map[string]Limit
+
+	ErrorsBeforeThrottle       int
+	ViolationsBeforeDisconnect int
+	ThrottleDelay              time.Duration
+	ViolationWindow            time.Duration
+}
+
+func DefaultRateLimits() RateLimits {
+	hostAction := Limit{Burst: 2, PerSecond: 0.5}
+	return RateLimits{
+		Connection: Limit{Burst: 20, PerSecond: 10},
+		PerType: map[string]Limit{
+			"start_game":      hostAction,
+			"next_round":      hostAction,
+			"reveal_answer":   hostAction,
+			"play_again":      hostAction,
+			"reset_game":      hostAction,
+			"update_settings": {Burst: 5, PerSecond: 1},
+			"submit_answer":   {Burst: 3, PerSecond: 1},
+			"sync":            {Burst: 3, PerSecond: 1},
+			"get_state":       {Burst: 3, PerSecond: 1},
+			"time_sync":       {Burst: 5, PerSecond: 2},
+		},
+		ErrorsBeforeThrottle:       3,
+		ViolationsBeforeDisconnect: 10,
+		ThrottleDelay:              500 * time.Millisecond,
+		ViolationWindow:            30 * time.Second,
+	}
+}
+
+type limitAction int
+
+const (
+	limitAllow limitAction = iota
+	limitError
+	limitThrottle
+	limitDisconnect
+)
+
+type inboundLimiter struct {
+	limits     RateLimits
+	conn       *tokenBucket
+	perType    map[string]*tokenBucket
+	violations int
+	lastAt     time.Time
+}
+
+func newInboundLimiter(limits RateLimits) *inboundLimiter {
+	l := &inboundLimiter{limits: limits, perType: make(map[string]*tokenBucket, len(limits.PerType))}
+	if limits.Connection.Burst > 0 {
+		l.conn = newTokenBucket(limits.Connection.Burst, limits.Connection.PerSecond)
+	}
+	for msgType, limit := range limits.PerType {
+		l.perType[msgType] = newTokenBucket(limit.Burst, limit.PerSecond)
+	}
+	return l
+}
+
+func (l *inboundLimiter) check(msgType string, now time.Time) limitAction {
+	allowed := l.conn == nil || l.conn.allow(now)
+	if b, ok := l.perType[msgType]; ok && allowed {
+		allowed = b.allow(now)
+	}
+	if allowed {
+		return limitAllow
+	}
+
+	if l.limits.ViolationWindow > 0 && now.Sub(l.lastAt) > l.limits.ViolationWindow {
+		l.violations = 0
+	}
+	l.violations++
+	l.lastAt = now
+
+	switch {
+	case l.limits.ViolationsBeforeDisconnect > 0 && l.violations > l.limits.ViolationsBeforeDisconnect:
+		return limitDisconnect
+	case l.violations > l.limits.ErrorsBeforeThrottle:
+		return limitThrottle
+	default:
+		return limitError
+	}
+}
@@ -0,0 +1,83 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTokenBucket_BurstThenRefill(t *testing.T) {
+	b := newTokenBucket(2, 1)
+	now := time.Now()
+
+	require.True(t, b.allow(now))
+	require.True(t, b.allow(now))
+	require.False(t, b.allow(now))
+
+	require.False(t, b.allow(now.Add(500*time.Millisecond)))
+	require.True(t, b.allow(now.Add(1100*time.Millisecond)))
+
+	require.True(t, b.allow(now.Add(time.Hour)))
+	require.True(t, b.allow(now.Add(time.Hour)))
+	require.False(t, b.allow(now.Add(time.Hour)))
+}
+
+func TestInboundLimiter_Escalates(t *testing.T) {
+	limits := RateLimits{
+		Connection:                 Limit{Burst: 1, PerSecond: 0},
+		ErrorsBeforeThrottle:       1,
+		ViolationsBeforeDisconnect: 2,
+		ViolationWindow:            time.Minute,
+	}
+	l := newInboundLimiter(limits)
+	now := time.Now()
+
+	require.Equal(t, limitAllow, l.check("submit_answer", now))
+	require.Equal(t, limitError, l.check("submit_answer", now))
+	require.Equal(t, limitThrottle, l.check("submit_answer", now))
+	require.Equal(t, limitDisconnect, l.check("submit_answer", now))
+}
+
+func TestInboundLimiter_PerTypeAndWindowReset(t *testing.T) {
+	limits := RateLimits{
+		Connection:                 Limit{Burst: 100, PerSecond: 100},
+		PerType:                    map[string]Limit{"start_game": {Burst: 1, PerSecond: 0}},
+		ErrorsBeforeThrottle:       1,
+		ViolationsBeforeDisconnect: 5,
+		ViolationWindow:            10 * time.Second,
+	}
+	l := newInboundLimiter(limits)
+	now := time.Now()
+
+	require.Equal(t, limitAllow, l.check("start_game", now))
+	require.Equal(t, limitError, l.check("start_game", now))
+	require.Equal(t, limitAllow, l.check("submit_answer", now))
+	require.Equal(t, limitThrottle, l.check("start_game", now))
+
+	require.Equal(t, limitError, l.check("start_game", now.Add(time.Minute)))
+}
+
+func TestClient_ThrottledMessageGetsRateLimitedReply(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	c := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+	c.limiter = newInboundLimiter(RateLimits{
+		Connection:                 Limit{Burst: 1, PerSecond: 0},
+		ErrorsBeforeThrottle:       1,
+		ViolationsBeforeDisconnect: 5,
+		ThrottleDelay:              time.Millisecond,
+		ViolationWindow:            time.Minute,
+	})
+
+	require.Equal(t, limitAllow, c.allowInbound("submit_answer", time.Now()))
+	require.Equal(t, limitError, c.allowInbound("submit_answer", time.Now()))
+	require.Equal(t, limitThrottle, c.allowInbound("submit_answer", time.Now()))
+
+	for i := 0; i < 2; i++ {
+		env := receiveType(t, c)
+		require.Equal(t, "error", env["type"])
+		require.Equal(t, "rate_limited", env["payload"].(map[string]interface{})["code"])
+	}
+	require.Equal(t, uint64(1), h.Stats().Throttled)
+}
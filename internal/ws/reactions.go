@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+)
+
+var ReactionEmojis = []string{"👍", "😂", "😮", "😢", "🔥", "👏", "🎉", "❤️"}
+
+var (
+	ErrInvalidReaction     = errors.New("invalid reaction")
+	ErrReactionRateLimited = errors.New("reaction rate limited")
+)
+
+type ReactPayload struct {
+	Emoji string `json:"emoji"`
+}
+
+type ReactionsPayload struct {
+	Counts map[string]int `json:"counts"`
+}
+
+type reactionBatch struct {
+	counts map[string]int
+}
+
+func validReaction(emoji string) bool {
+	for _, e := range ReactionEmojis {
+		if e == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) react(room *game.Room, emoji string) error {
+	if !validReaction(emoji) {
+		return ErrInvalidReaction
+	}
+	if room.CurrentPhase() != game.PhaseResults {
+		return game.ErrBadPhase
+	}
+	if !c.reactions.allow(time.Now()) {
+		return ErrReactionRateLimited
+	}
+
+	c.hub.addReaction(c.roomCode, emoji)
+	return nil
+}
+
+func (h *Hub) addReaction(roomCode, emoji string) {
+	rc := strings.ToUpper(roomCode)
+
+	h.reactionsMu.Lock()
+	defer h.reactionsMu.Unlock()
+
+	batch, ok := h.reactions[rc]
+	if !ok {
+		batch = &reactionBatch{counts: make(map[string]int)}
+		h.reactions[rc] = batch
+		go h.flushReactions(rc)
+	}
+	batch.counts[emoji]++
+}
+
+func (h *Hub) flushReactions(roomCode string) {
+	ticker := time.NewTicker(reactionInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.reactionsMu.Lock()
+		batch := h.reactions[roomCode]
+		if batch == nil || len(batch.counts) == 0 {
+			delete(h.reactions, roomCode)
+			h.reactionsMu.Unlock()
+			return
+		}
+		counts := batch.counts
+		batch.counts = make(map[string]int)
+		h.reactionsMu.Unlock()
+
+		h.broadcastEphemeral(roomCode, Envelope{Type: "reactions", Payload: ReactionsPayload{Counts: counts}})
+	}
+}
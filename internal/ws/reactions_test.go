@@ -0,0 +1,45 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHub_Reactions_AggregatedPerInterval(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	c := registerTestClient(t, h, "ABCD", "alice", ProtocolV2)
+
+	h.addReaction("ABCD", "🔥")
+	h.addReaction("abcd", "🔥")
+	h.addReaction("ABCD", "👏")
+
+	env := receiveType(t, c)
+	require.Equal(t, "reactions", env["type"])
+	require.NotContains(t, env, "seq")
+	require.Equal(t, map[string]interface{}{"🔥": float64(2), "👏": float64(1)}, env["payload"].(map[string]interface{})["counts"])
+
+	events, _, ok := h.replaySince("ABCD", "", 0, frameKey{version: ProtocolV2})
+	require.True(t, ok)
+	require.Empty(t, events)
+	require.Eventually(t, func() bool {
+		h.reactionsMu.Lock()
+		defer h.reactionsMu.Unlock()
+		return len(h.reactions) == 0
+	}, 2*reactionInterval+reactionInterval/2, reactionInterval/10)
+}
+
+func TestClient_React_Validation(t *testing.T) {
+	h := NewHub(nil, zap.NewNop(), Config{})
+	room := roomWithPlayers(1)
+	c := &Client{hub: h, roomCode: "ABCD", playerID: "p0", reactions: newTokenBucket(1, 0)}
+
+	require.ErrorIs(t, c.react(room, "🔥"), game.ErrBadPhase)
+
+	room.Phase = game.PhaseResults
+	require.ErrorIs(t, c.react(room, "🍕"), ErrInvalidReaction)
+	require.NoError(t, c.react(room, "🔥"))
+	require.ErrorIs(t, c.react(room, "🔥"), ErrReactionRateLimited)
+}
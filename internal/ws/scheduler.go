@@ -24,25 +24,69 @@ func (h *Hub) scheduleAnsweringDeadline(room *game.Room, roomCode string, gen in
 	}
 
 	if payload, ok := room.FinishRoundIfDeadlinePassed(); ok {
-		h.Broadcast(roomCode, Envelope{Type: "round_results", Payload: payload})
-		h.Broadcast(roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
-
-		after := room.Snapshot()
-		if after.RoundNumber >= h.svc.MaxRounds() {
-			gameOver := h.svc.BuildLeaderboard(room)
-			h.Broadcast(roomCode, Envelope{Type: "game_over", Payload: gameOver})
-			return
-		}
+		h.roundFinished(room, roomCode, payload, gen)
+	}
+}
 
-		go h.scheduleNextRound(room, roomCode, h.svc.ResultsPause())
+func (h *Hub) finishRoundIfAllAnswered(room *game.Room, roomCode string) {
+	payload, ok := room.FinishRoundIfAllAnswered()
+	if !ok {
+		return
 	}
+
+	gen := h.bumpRoundGen(roomCode)
+	h.roundFinished(room, roomCode, payload, gen)
 }
 
-func (h *Hub) scheduleNextRound(room *game.Room, roomCode string, delay time.Duration) {
+func (h *Hub) roundFinished(room *game.Room, roomCode string, payload *game.RoundResultsPayload, gen int64) {
+	after := room.Snapshot()
+	h.BroadcastEach(roomCode, "round_results", payload, personalRoundResults(payload, after))
+	h.Broadcast(roomCode, Envelope{Type: "room_state", Payload: after})
+
+	if after.RoundNumber >= h.svc.MaxRounds() {
+		gameOver := h.svc.BuildLeaderboard(room)
+		h.Broadcast(roomCode, Envelope{Type: "game_over", Payload: gameOver})
+		return
+	}
+	if after.Settings.HostPaced {
+		return
+	}
+
+	go h.scheduleNextRound(room, roomCode, h.svc.ResultsPause(), gen)
+}
+
+func personalRoundResults(payload *game.RoundResultsPayload, snap game.RoomSnapshot) Personalize {
+	ranks := make(map[string]int, len(snap.Players))
+	for _, e := range game.BuildLeaderboard(snap).Leaderboard {
+		ranks[e.PlayerID] = e.Place
+	}
+	answers := make(map[string]string, len(payload.Results))
+	for _, r := range payload.Results {
+		answers[r.PlayerID] = r.SelectedOptionID
+	}
+
+	return func(playerID string) interface{} {
+		rank, ok := ranks[playerID]
+		if !ok {
+			return nil
+		}
+		return PersonalRoundResults{
+			RoundResultsPayload: payload,
+			YourAnswer:          answers[playerID],
+			YourRank:            rank,
+		}
+	}
+}
+
+func (h *Hub) scheduleNextRound(room *game.Room, roomCode string, delay time.Duration, gen int64) {
 	time.Sleep(delay)
 
+	if !h.isCurrentGen(roomCode, gen) {
+		return
+	}
+
 	snap := room.Snapshot()
-	if snap.HostID == "" {
+	if snap.HostID == "" || snap.Paused || snap.Settings.HostPaced {
 		return
 	}
 	if snap.RoundNumber >= h.svc.MaxRounds() {
@@ -52,12 +96,89 @@ func (h *Hub) scheduleNextRound(room *game.Room, roomCode string, delay time.Dur
 	}
 
 	if err := h.svc.StartRound(context.Background(), room, snap.HostID); err != nil {
-		h.Broadcast(roomCode, Envelope{Type: "error", Payload: map[string]string{"message": err.Error()}})
+		h.Broadcast(roomCode, errorEnvelope(err.Error()))
+		return
+	}
+
+	h.Broadcast(roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+
+	next := h.bumpRoundGen(roomCode)
+	go h.scheduleAnsweringDeadline(room, roomCode, next)
+}
+
+func (h *Hub) scheduleCountdown(room *game.Room, roomCode string, gen int64) {
+	snap := room.Snapshot()
+	if snap.Phase != game.PhaseCountdown || snap.Deadline == 0 {
+		return
+	}
+
+	wait := time.Until(time.UnixMilli(snap.Deadline))
+	if wait < 0 {
+		wait = 0
+	}
+	time.Sleep(wait)
+
+	if !h.isCurrentGen(roomCode, gen) {
+		return
+	}
+
+	snap = room.Snapshot()
+	if snap.Phase != game.PhaseCountdown || snap.HostID == "" {
+		return
+	}
+
+	if err := h.svc.StartRound(context.Background(), room, snap.HostID); err != nil {
+		h.Broadcast(roomCode, errorEnvelope(err.Error()))
 		return
 	}
 
 	h.Broadcast(roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
 
+	next := h.bumpRoundGen(roomCode)
+	go h.scheduleAnsweringDeadline(room, roomCode, next)
+}
+
+func (h *Hub) recheckReadyQuorum(room *game.Room, roomCode string) {
+	started, cancelled := h.svc.CheckReadyQuorum(room)
+	if !started && !cancelled {
+		return
+	}
+
 	gen := h.bumpRoundGen(roomCode)
-	go h.scheduleAnsweringDeadline(room, roomCode, gen)
+	if started {
+		go h.scheduleCountdown(room, roomCode, gen)
+	}
+}
+
+func (h *Hub) resumeSchedule(room *game.Room, roomCode string) {
+	gen := h.bumpRoundGen(roomCode)
+
+	snap := room.Snapshot()
+	switch snap.Phase {
+	case game.PhaseAnswering:
+		go h.scheduleAnsweringDeadline(room, roomCode, gen)
+	case game.PhaseResults:
+		if snap.RoundNumber < h.svc.MaxRounds() && !snap.Settings.HostPaced {
+			go h.scheduleNextRound(room, roomCode, h.svc.ResultsPause(), gen)
+		}
+	}
+}
+
+func (h *Hub) scheduleRemoval(room *game.Room, roomCode string, playerID string, session int) {
+	time.Sleep(h.cfg.ReconnectGrace)
+
+	newHostID, hostChanged, removed := room.RemoveIfOffline(playerID, session)
+	if !removed {
+		return
+	}
+
+	if hostChanged {
+		h.Broadcast(roomCode, Envelope{Type: "host_changed", Payload: HostChangedPayload{
+			OldHostID: playerID,
+			NewHostID: newHostID,
+		}})
+	}
+	h.recheckReadyQuorum(room, roomCode)
+	h.Broadcast(roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+	h.finishRoundIfAllAnswered(room, roomCode)
 }
@@ -0,0 +1,125 @@
+package ws
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+func JSONSchema() ([]byte, error) {
+	b := &schemaBuilder{defs: make(map[string]interface{})}
+
+	var client, server []interface{}
+	for _, spec := range Catalog {
+		props := map[string]interface{}{
+			"type":    map[string]interface{}{"const": spec.Type},
+			"payload": b.schemaFor(reflect.TypeOf(spec.Payload)),
+		}
+		msg := map[string]interface{}{
+			"type":                 "object",
+			"description":          spec.Summary,
+			"required":             []string{"type"},
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if spec.Direction == ClientToServer {
+			client = append(client, msg)
+		} else {
+			props["seq"] = map[string]interface{}{"type": "integer", "minimum": 0}
+			server = append(server, msg)
+		}
+	}
+	b.defs["ClientMessage"] = map[string]interface{}{"oneOf": client}
+	b.defs["ServerMessage"] = map[string]interface{}{"oneOf": server}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+		"title":   "Quiz WebSocket protocol",
+		"oneOf": []interface{}{
+			map[string]interface{}{"$ref": "#/$defs/ClientMessage"},
+			map[string]interface{}{"$ref": "#/$defs/ServerMessage"},
+		},
+		"$defs": b.defs,
+	}, "", "  ")
+}
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := b.defs[name]; !ok {
+			b.defs[name] = nil
+			b.defs[name] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	required := []string{}
+	b.collectFields(t, props, &required)
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func (b *schemaBuilder) collectFields(t reflect.Type, props map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			b.collectFields(ft, props, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		props[name] = b.schemaFor(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
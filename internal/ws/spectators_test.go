@@ -0,0 +1,68 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSE_SpectatorWatchesButCannotPlay(t *testing.T) {
+	_, room, srv := newSSEServer(t, Config{})
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Alex"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Judge","protocolVersion":2,"role":"spectator"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var judge JoinResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&judge))
+
+	snap := room.Snapshot()
+	require.Equal(t, 1, snap.Spectators)
+	require.Len(t, snap.Players, 1)
+	require.NotEqual(t, judge.PlayerID, snap.HostID)
+
+	events, err := http.Get(srv.URL + "/events?session=" + judge.SessionID)
+	require.NoError(t, err)
+	defer events.Body.Close()
+	stream := bufio.NewReader(events.Body)
+	state := nextEvent(t, stream, "room_state")["payload"].(map[string]interface{})
+	require.EqualValues(t, 1, state["spectators"])
+
+	for _, msg := range []string{
+		`{"type":"set_ready","payload":{"ready":true}}`,
+		`{"type":"start_game"}`,
+		`{"type":"chat_send","payload":{"text":"hi"}}`,
+	} {
+		resp = postJSON(t, srv.URL+"/actions", judge.SessionID, msg)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		payload := nextEvent(t, stream, "error")["payload"].(map[string]interface{})
+		require.Equal(t, game.ErrSpectator.Error(), payload["message"])
+		require.Equal(t, "spectator", payload["code"])
+	}
+
+	resp = postJSON(t, srv.URL+"/actions", judge.SessionID, `{"type":"time_sync","payload":{"clientTime":1}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.Equal(t, "time_sync", nextEvent(t, stream, "time_sync")["type"])
+}
+
+func TestSSE_SpectatorLimit(t *testing.T) {
+	_, _, srv := newSSEServer(t, Config{})
+
+	for i := 0; i < game.DefaultMaxSpectators; i++ {
+		resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"role":"spectator"}}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"role":"spectator"}}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var env struct {
+		Payload ErrorPayload `json:"payload"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+	require.Equal(t, "spectators_full", env.Payload.Code)
+}
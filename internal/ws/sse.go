@@ -0,0 +1,362 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"go.uber.org/zap"
+)
+
+type JoinResponse struct {
+	SessionID   string `json:"sessionId"`
+	PlayerID    string `json:"playerId"`
+	ResumeToken string `json:"resumeToken"`
+}
+
+type httpSession struct {
+	id     string
+	client *Client
+	room   *game.Room
+
+	mu       sync.Mutex
+	stop     chan struct{}
+	lastSeen time.Time
+
+	streamMu sync.Mutex
+	actionMu sync.Mutex
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (h *Hub) ServeJoin(w http.ResponseWriter, r *http.Request, roomCode string) {
+	room, ok := h.svc.GetRoom(roomCode)
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	if !h.checkOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	msg, err := h.readHTTPMessage(w, r)
+	if err != nil {
+		writeHTTPEnvelope(w, http.StatusBadRequest, errorEnvelope("expected join_room"))
+		return
+	}
+	adm, errEnv, ok := h.admit(room, msg)
+	if !ok {
+		writeHTTPEnvelope(w, http.StatusBadRequest, errEnv)
+		return
+	}
+
+	s := &httpSession{
+		id:       newID(),
+		client:   h.newClient(roomCode, adm),
+		room:     room,
+		lastSeen: time.Now(),
+		done:     make(chan struct{}),
+	}
+	s.client.resyncOnFull = true
+	h.sessionsMu.Lock()
+	h.sessions[s.id] = s
+	h.sessionsMu.Unlock()
+
+	h.attach(room, s.client, adm)
+	go h.watchSession(s)
+
+	h.log.Info("http session opened",
+		zap.String("room", s.client.roomCode),
+		zap.String("player_id", s.client.playerID),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(JoinResponse{
+		SessionID:   s.id,
+		PlayerID:    adm.player.ID,
+		ResumeToken: adm.player.ResumeToken,
+	})
+}
+
+func (h *Hub) ServeEvents(w http.ResponseWriter, r *http.Request, roomCode string) {
+	s, ok := h.lookupSession(r, roomCode)
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	stop := s.attachStream()
+	defer s.detachStream(stop)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	rc := http.NewResponseController(w)
+	ticker := time.NewTicker(h.cfg.PingPeriod)
+	defer ticker.Stop()
+
+	if !h.writeSSE(w, flusher, rc, s, h.resync(s)) {
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-stop:
+			return
+		case <-s.done:
+			return
+
+		case <-s.client.done:
+			_ = rc.SetWriteDeadline(time.Now().Add(h.cfg.WriteWait))
+			for _, message := range s.client.pending() {
+				if _, err := w.Write(sseFrame(message)); err != nil {
+					break
+				}
+			}
+			_, _ = w.Write([]byte("event: close\ndata: {}\n\n"))
+			flusher.Flush()
+			h.closeSession(s)
+			return
+
+		case message := <-s.client.send:
+			if !h.writeSSE(w, flusher, rc, s, [][]byte{message}) {
+				return
+			}
+			if !h.writeSSE(w, flusher, rc, s, h.resync(s)) {
+				return
+			}
+
+		case <-ticker.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(h.cfg.WriteWait))
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				h.log.Warn("sse ping failed",
+					zap.String("room", s.client.roomCode),
+					zap.String("player_id", s.client.playerID),
+					zap.Error(err),
+				)
+				return
+			}
+			flusher.Flush()
+			s.touch()
+		}
+	}
+}
+
+func (h *Hub) ServeActions(w http.ResponseWriter, r *http.Request, roomCode string) {
+	s, ok := h.lookupSession(r, roomCode)
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	msg, err := h.readHTTPMessage(w, r)
+	if err != nil {
+		writeHTTPEnvelope(w, http.StatusBadRequest, errorEnvelope("bad message"))
+		return
+	}
+	s.touch()
+
+	s.actionMu.Lock()
+	keep := s.client.handle(s.room, msg)
+	s.actionMu.Unlock()
+	if !keep {
+		h.closeSession(s)
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Hub) lookupSession(r *http.Request, roomCode string) (*httpSession, bool) {
+	id := r.Header.Get("X-Session-Id")
+	if id == "" {
+		id = r.URL.Query().Get("session")
+	}
+
+	h.sessionsMu.Lock()
+	s, ok := h.sessions[id]
+	h.sessionsMu.Unlock()
+	if !ok || !strings.EqualFold(s.client.roomCode, roomCode) {
+		return nil, false
+	}
+	return s, true
+}
+
+func (h *Hub) watchSession(s *httpSession) {
+	ticker := time.NewTicker(h.cfg.PingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			if s.idle(now) > h.cfg.PongWait {
+				h.log.Info("http session timed out",
+					zap.String("room", s.client.roomCode),
+					zap.String("player_id", s.client.playerID),
+				)
+				h.closeSession(s)
+				return
+			}
+		}
+	}
+}
+
+func (h *Hub) closeSession(s *httpSession) {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		h.sessionsMu.Lock()
+		delete(h.sessions, s.id)
+		h.sessionsMu.Unlock()
+
+		s.client.disconnect(s.room)
+	})
+}
+
+func (h *Hub) writeSSE(w http.ResponseWriter, flusher http.Flusher, rc *http.ResponseController, s *httpSession, messages [][]byte) bool {
+	if len(messages) == 0 {
+		return true
+	}
+	_ = rc.SetWriteDeadline(time.Now().Add(h.cfg.WriteWait))
+	for _, message := range messages {
+		if _, err := w.Write(sseFrame(message)); err != nil {
+			h.log.Warn("sse write failed",
+				zap.String("room", s.client.roomCode),
+				zap.String("player_id", s.client.playerID),
+				zap.Error(err),
+			)
+			return false
+		}
+	}
+	flusher.Flush()
+	return true
+}
+
+func (h *Hub) resync(s *httpSession) [][]byte {
+	c := s.client
+	if len(c.send) > 0 {
+		return nil
+	}
+	c.syncMu.Lock()
+	stale, lastSeq := c.stale, c.queuedSeq
+	c.syncMu.Unlock()
+	if !stale {
+		return nil
+	}
+
+	var out [][]byte
+	fullState := false
+	for {
+		events, currentSeq, ok := h.replaySince(c.roomCode, c.playerID, lastSeq, c.frameKey())
+		if !ok {
+			b, seq, err := h.fullStateAt(c.roomCode, s.room, c.frameKey())
+			if err != nil {
+				h.log.Error("sse full state failed",
+					zap.String("room", c.roomCode),
+					zap.String("player_id", c.playerID),
+					zap.Error(err),
+				)
+				return out
+			}
+			events, currentSeq, fullState = [][]byte{b}, seq, true
+		}
+		out = append(out, events...)
+
+		c.syncMu.Lock()
+		if c.droppedSeq <= currentSeq {
+			if currentSeq > c.queuedSeq {
+				c.queuedSeq = currentSeq
+			}
+			c.stale = false
+			c.syncMu.Unlock()
+			break
+		}
+		c.syncMu.Unlock()
+		lastSeq = currentSeq
+	}
+
+	h.log.Info("sse session resynced after backlog overflow",
+		zap.String("room", c.roomCode),
+		zap.String("player_id", c.playerID),
+		zap.Int("replayed", len(out)),
+		zap.Bool("full_state", fullState),
+	)
+	return out
+}
+
+func (h *Hub) readHTTPMessage(w http.ResponseWriter, r *http.Request) (clientMsg, error) {
+	var msg clientMsg
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.cfg.MaxMessageSize)).Decode(&msg)
+	return msg, err
+}
+
+func writeHTTPEnvelope(w http.ResponseWriter, status int, env Envelope) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(env)
+}
+
+func sseFrame(message []byte) []byte {
+	frame := make([]byte, 0, len(message)+8)
+	frame = append(frame, "data: "...)
+	frame = append(frame, message...)
+	return append(frame, "\n\n"...)
+}
+
+func (s *httpSession) attachStream() chan struct{} {
+	s.mu.Lock()
+	if s.stop != nil {
+		close(s.stop)
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+	s.mu.Unlock()
+
+	s.streamMu.Lock()
+	return stop
+}
+
+func (s *httpSession) detachStream(stop chan struct{}) {
+	s.streamMu.Unlock()
+
+	s.mu.Lock()
+	if s.stop == stop {
+		s.stop = nil
+	}
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *httpSession) touch() {
+	s.mu.Lock()
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *httpSession) idle(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		return 0
+	}
+	return now.Sub(s.lastSeen)
+}
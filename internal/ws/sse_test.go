@@ -0,0 +1,237 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/ArtemMoroz51/FinalProject/internal/service"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newSSEServer(t *testing.T, cfg Config) (*Hub, *game.Room, *httptest.Server) {
+	t.Helper()
+
+	svc := service.NewGameService(game.NewRoomManager(), nil, service.Config{})
+	room := svc.CreateRoom()
+	h := NewHub(svc, zap.NewNop(), cfg)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/join", func(w http.ResponseWriter, r *http.Request) { h.ServeJoin(w, r, room.Code) })
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) { h.ServeEvents(w, r, room.Code) })
+	mux.HandleFunc("/actions", func(w http.ResponseWriter, r *http.Request) { h.ServeActions(w, r, room.Code) })
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return h, room, srv
+}
+
+func postJSON(t *testing.T, url, session, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	if session != "" {
+		req.Header.Set("X-Session-Id", session)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func nextEvent(t *testing.T, r *bufio.Reader, msgType string) map[string]interface{} {
+	t.Helper()
+
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		var env map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(data), &env))
+		if env["type"] == msgType {
+			return env
+		}
+	}
+}
+
+func TestSSE_JoinStreamAndAct(t *testing.T) {
+	_, room, srv := newSSEServer(t, Config{})
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Alex","protocolVersion":2}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var joined JoinResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&joined))
+	require.NotEmpty(t, joined.SessionID)
+
+	events, err := http.Get(srv.URL + "/events?session=" + joined.SessionID)
+	require.NoError(t, err)
+	defer events.Body.Close()
+	require.Equal(t, "text/event-stream", events.Header.Get("Content-Type"))
+
+	stream := bufio.NewReader(events.Body)
+	welcome := nextEvent(t, stream, "welcome")
+	require.EqualValues(t, ProtocolV2, welcome["payload"].(map[string]interface{})["protocolVersion"])
+	require.NotContains(t, welcome["payload"], "deprecated")
+	require.Equal(t, joined.PlayerID, nextEvent(t, stream, "joined")["payload"].(map[string]interface{})["playerId"])
+
+	resp = postJSON(t, srv.URL+"/actions", joined.SessionID, `{"type":"chat_send","payload":{"text":"hi"}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.Equal(t, "hi", nextEvent(t, stream, "chat_message")["payload"].(map[string]interface{})["text"])
+
+	require.True(t, room.Snapshot().Players[0].Online)
+}
+
+func TestSSE_RejectsUnknownSessionAndBadJoin(t *testing.T) {
+	_, _, srv := newSSEServer(t, Config{})
+
+	resp := postJSON(t, srv.URL+"/actions", "nope", `{"type":"sync","payload":{"lastSeq":0}}`)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = postJSON(t, srv.URL+"/join", "", `{"type":"start_game"}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSSE_IdleSessionDisconnects(t *testing.T) {
+	h, room, srv := newSSEServer(t, Config{PongWait: 100 * time.Millisecond, PingPeriod: 20 * time.Millisecond})
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Alex"}}`)
+	var joined JoinResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&joined))
+
+	require.Eventually(t, func() bool {
+		h.sessionsMu.Lock()
+		defer h.sessionsMu.Unlock()
+		_, ok := h.sessions[joined.SessionID]
+		return !ok
+	}, 2*time.Second, 10*time.Millisecond)
+	require.False(t, room.Snapshot().Players[0].Online)
+
+	resp = postJSON(t, srv.URL+"/actions", joined.SessionID, `{"type":"get_state"}`)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestSSE_DetachedBacklogOverflowResyncs(t *testing.T) {
+	h, room, srv := newSSEServer(t, Config{SendBufferSize: 4})
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Alex","protocolVersion":2}}`)
+	var joined JoinResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&joined))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 300; i++ {
+			h.Broadcast(room.Code, Envelope{Type: "answer_accepted", Payload: AnswerAcceptedPayload{OK: true}})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("hub blocked by detached sse session")
+	}
+
+	events, err := http.Get(srv.URL + "/events?session=" + joined.SessionID)
+	require.NoError(t, err)
+	defer events.Body.Close()
+
+	state := nextEvent(t, bufio.NewReader(events.Body), "room_state")
+	require.GreaterOrEqual(t, state["seq"], float64(300))
+	require.True(t, room.Snapshot().Players[0].Online)
+}
+
+func TestSSE_WelcomeDeprecatesOnlyV1(t *testing.T) {
+	_, _, srv := newSSEServer(t, Config{})
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Old"}}`)
+	var joined JoinResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&joined))
+
+	events, err := http.Get(srv.URL + "/events?session=" + joined.SessionID)
+	require.NoError(t, err)
+	defer events.Body.Close()
+
+	welcome := nextEvent(t, bufio.NewReader(events.Body), "welcome")["payload"].(map[string]interface{})
+	require.EqualValues(t, ProtocolV1, welcome["protocolVersion"])
+	require.Equal(t, true, welcome["deprecated"])
+}
+
+func openOverflowedSession(t *testing.T, h *Hub, room *game.Room, srv *httptest.Server, messages int) *bufio.Reader {
+	t.Helper()
+
+	resp := postJSON(t, srv.URL+"/join", "", `{"type":"join_room","payload":{"name":"Alex","protocolVersion":2}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var joined JoinResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&joined))
+
+	h.sessionsMu.Lock()
+	c := h.sessions[joined.SessionID].client
+	h.sessionsMu.Unlock()
+
+	for i := 0; i < messages; i++ {
+		h.Broadcast(room.Code, Envelope{Type: "chat_message", Payload: game.ChatMessage{ID: uint64(i + 1), Text: "hi"}})
+	}
+	h.historyMu.Lock()
+	lastSeq := h.history[room.Code].seq
+	h.historyMu.Unlock()
+	require.Eventually(t, func() bool {
+		c.syncMu.Lock()
+		defer c.syncMu.Unlock()
+		return c.stale && c.droppedSeq == lastSeq
+	}, 2*time.Second, 5*time.Millisecond)
+
+	events, err := http.Get(srv.URL + "/events?session=" + joined.SessionID)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = events.Body.Close() })
+	return bufio.NewReader(events.Body)
+}
+
+func TestSSE_BacklogOverflowReplaysMissedEvents(t *testing.T) {
+	h, room, srv := newSSEServer(t, Config{SendBufferSize: 4})
+	stream := openOverflowedSession(t, h, room, srv, 10)
+
+	var prevSeq float64
+	var chats []float64
+	for len(chats) < 10 {
+		line, err := stream.ReadString('\n')
+		require.NoError(t, err)
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		var env map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(data), &env))
+		seq, ok := env["seq"].(float64)
+		if !ok {
+			continue
+		}
+		if prevSeq != 0 {
+			require.Equal(t, prevSeq+1, seq, "gap after seq %v", prevSeq)
+		}
+		prevSeq = seq
+		if env["type"] == "chat_message" {
+			chats = append(chats, env["payload"].(map[string]interface{})["id"].(float64))
+		}
+	}
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, chats)
+}
+
+func TestSSE_BacklogOverflowFallsBackToFullState(t *testing.T) {
+	h, room, srv := newSSEServer(t, Config{SendBufferSize: 4})
+	stream := openOverflowedSession(t, h, room, srv, eventHistorySize+10)
+
+	state := nextEvent(t, stream, "room_state")
+	h.historyMu.Lock()
+	lastSeq := h.history[room.Code].seq
+	h.historyMu.Unlock()
+	require.EqualValues(t, lastSeq, state["seq"])
+}
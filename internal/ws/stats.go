@@ -0,0 +1,44 @@
+package ws
+
+import "sync/atomic"
+
+type Stats struct {
+	Connections       int    `json:"connections"`
+	Rooms             int    `json:"rooms"`
+	MessagesReceived  uint64 `json:"messagesReceived"`
+	MessagesRejected  uint64 `json:"messagesRejected"`
+	RateLimited       uint64 `json:"rateLimited"`
+	Throttled         uint64 `json:"throttled"`
+	PolicyDisconnects uint64 `json:"policyDisconnects"`
+	OriginRejected    uint64 `json:"originRejected"`
+}
+
+type counters struct {
+	received          atomic.Uint64
+	rejected          atomic.Uint64
+	rateLimited       atomic.Uint64
+	throttled         atomic.Uint64
+	policyDisconnects atomic.Uint64
+	originRejected    atomic.Uint64
+}
+
+func (h *Hub) Stats() Stats {
+	h.mu.RLock()
+	connections := 0
+	for _, roomClients := range h.clientsByRoom {
+		connections += len(roomClients)
+	}
+	rooms := len(h.clientsByRoom)
+	h.mu.RUnlock()
+
+	return Stats{
+		Connections:       connections,
+		Rooms:             rooms,
+		MessagesReceived:  h.counters.received.Load(),
+		MessagesRejected:  h.counters.rejected.Load(),
+		RateLimited:       h.counters.rateLimited.Load(),
+		Throttled:         h.counters.throttled.Load(),
+		PolicyDisconnects: h.counters.policyDisconnects.Load(),
+		OriginRejected:    h.counters.originRejected.Load(),
+	}
+}
@@ -0,0 +1,314 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+)
+
+const (
+	ProtocolV1 = 1
+	ProtocolV2 = 2
+	ProtocolV3 = 3
+
+	CurrentProtocolVersion    = ProtocolV3
+	MinProtocolVersion        = ProtocolV1
+	DeprecatedProtocolVersion = ProtocolV1
+)
+
+var ErrUnsupportedProtocol = errors.New("unsupported protocol version")
+
+var serverFeatures = []string{
+	"seq_sync",
+	"resume",
+	"host_transfer",
+	"ready_check",
+	"pause",
+	"host_paced",
+	"play_again",
+	"avatars",
+	"state_patch",
+	"time_sync",
+	"chat",
+	"reactions",
+	"msgpack",
+	"display",
+	"spectators",
+}
+
+type WelcomePayload struct {
+	ProtocolVersion int      `json:"protocolVersion"`
+	Deprecated      bool     `json:"deprecated,omitempty"`
+	Features        []string `json:"features"`
+	Capabilities    []string `json:"capabilities"`
+}
+
+type frames struct {
+	mu         sync.Mutex
+	env        Envelope
+	controller *Envelope
+	state      *Envelope
+	deadline   int64
+	enc        map[frameKey]stampedFrame
+}
+
+type stampedFrame struct {
+	remainingMs int64
+	frame       []byte
+}
+
+func negotiateVersion(requested int) (int, error) {
+	if requested == 0 {
+		return ProtocolV1, nil
+	}
+	if requested < MinProtocolVersion {
+		return 0, ErrUnsupportedProtocol
+	}
+	if requested > CurrentProtocolVersion {
+		return CurrentProtocolVersion, nil
+	}
+	return requested, nil
+}
+
+func acceptedCapabilities(requested []string) []string {
+	accepted := make([]string, 0, len(requested))
+	for _, c := range requested {
+		for _, f := range serverFeatures {
+			if c == f {
+				accepted = append(accepted, c)
+				break
+			}
+		}
+	}
+	return accepted
+}
+
+func newFrames(env Envelope) *frames {
+	f := &frames{env: env, enc: make(map[frameKey]stampedFrame)}
+	if controllerEnv, ok := controllerEnvelope(env); ok {
+		f.controller = &controllerEnv
+		f.deadline = env.Payload.(game.RoomSnapshot).Deadline
+	}
+	return f
+}
+
+func (f *frames) setState(env Envelope) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.state = &env
+}
+
+func (f *frames) get(key frameKey, now time.Time) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key.controller = key.controller && f.controller != nil
+	var remainingMs int64
+	if f.timed(key) {
+		remainingMs = game.RemainingMs(f.deadline, now)
+	}
+	return f.getLocked(key, remainingMs)
+}
+
+func (f *frames) getLocked(key frameKey, remainingMs int64) ([]byte, error) {
+	if s, ok := f.enc[key]; ok && s.remainingMs == remainingMs {
+		return s.frame, nil
+	}
+
+	var b []byte
+	var err error
+	if key.binary {
+		text := key
+		text.binary = false
+		if b, err = f.getLocked(text, remainingMs); err == nil {
+			b, err = jsonToMsgpack(b)
+		}
+	} else {
+		b, err = encodeForVersion(f.envelope(key, remainingMs), key.version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.enc[key] = stampedFrame{remainingMs: remainingMs, frame: b}
+	return b, nil
+}
+
+func (f *frames) envelope(key frameKey, remainingMs int64) Envelope {
+	env := f.env
+	switch {
+	case key.controller:
+		env = *f.controller
+	case key.version >= ProtocolV3 && f.state != nil:
+		env = *f.state
+	}
+	if f.timed(key) {
+		env.Payload = withRemainingMs(env.Payload, remainingMs)
+	}
+	return env
+}
+
+func (f *frames) timed(key frameKey) bool {
+	return f.controller != nil && (key.controller || key.version >= ProtocolV2)
+}
+
+func withRemainingMs(payload interface{}, remainingMs int64) interface{} {
+	switch p := payload.(type) {
+	case game.RoomSnapshot:
+		p.RemainingMs = remainingMs
+		return p
+	case VersionedRoomState:
+		p.RemainingMs = remainingMs
+		return p
+	case RoomPatchPayload:
+		p.RemainingMs = remainingMs
+		return p
+	case ControllerState:
+		p.RemainingMs = remainingMs
+		return p
+	}
+	return payload
+}
+
+func encodeForVersion(env Envelope, version int) ([]byte, error) {
+	if version == ProtocolV1 {
+		env.Seq = 0
+		if adapt, ok := v1Adapters[env.Type]; ok {
+			env.Payload = adapt(env.Payload)
+		}
+	}
+	return json.Marshal(env)
+}
+
+type playerV1 struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type roomSnapshotV1 struct {
+	Code        string         `json:"code"`
+	Phase       game.Phase     `json:"phase"`
+	HostID      string         `json:"hostId"`
+	RoundNumber int            `json:"roundNumber"`
+	Question    string         `json:"question,omitempty"`
+	Options     []game.Option  `json:"options,omitempty"`
+	Deadline    int64          `json:"deadline,omitempty"`
+	Players     []playerV1     `json:"players"`
+	Scores      map[string]int `json:"scores"`
+}
+
+type roundResultV1 struct {
+	PlayerID         string `json:"playerId"`
+	Name             string `json:"name"`
+	SelectedOptionID string `json:"selectedOptionId,omitempty"`
+	Correct          bool   `json:"correct"`
+	Score            int    `json:"score"`
+}
+
+type roundResultsV1 struct {
+	Code            string          `json:"code"`
+	RoundNumber     int             `json:"roundNumber"`
+	Question        string          `json:"question"`
+	Options         []game.Option   `json:"options"`
+	CorrectOptionID string          `json:"correctOptionId"`
+	Results         []roundResultV1 `json:"results"`
+}
+
+type leaderboardEntryV1 struct {
+	Place    int    `json:"place"`
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Score    int    `json:"score"`
+}
+
+type gameOverV1 struct {
+	Code         string               `json:"code"`
+	RoundsPlayed int                  `json:"roundsPlayed"`
+	Leaderboard  []leaderboardEntryV1 `json:"leaderboard"`
+}
+
+var v1Adapters = map[string]func(interface{}) interface{}{
+	"room_state":    roomStateV1,
+	"player_joined": playerJoinedV1,
+	"round_results": roundResultsToV1,
+	"game_over":     gameOverToV1,
+}
+
+func roomStateV1(payload interface{}) interface{} {
+	snap, ok := payload.(game.RoomSnapshot)
+	if !ok {
+		return payload
+	}
+	players := make([]playerV1, 0, len(snap.Players))
+	for _, p := range snap.Players {
+		players = append(players, playerV1{ID: p.ID, Name: p.Name})
+	}
+	return roomSnapshotV1{
+		Code:        snap.Code,
+		Phase:       snap.Phase,
+		HostID:      snap.HostID,
+		RoundNumber: snap.RoundNumber,
+		Question:    snap.Question,
+		Options:     snap.Options,
+		Deadline:    snap.Deadline,
+		Players:     players,
+		Scores:      snap.Scores,
+	}
+}
+
+func playerJoinedV1(payload interface{}) interface{} {
+	p, ok := payload.(*game.Player)
+	if !ok {
+		return payload
+	}
+	return playerV1{ID: p.ID, Name: p.Name}
+}
+
+func roundResultsToV1(payload interface{}) interface{} {
+	rr, ok := payload.(*game.RoundResultsPayload)
+	if !ok {
+		return payload
+	}
+	results := make([]roundResultV1, 0, len(rr.Results))
+	for _, r := range rr.Results {
+		results = append(results, roundResultV1{
+			PlayerID:         r.PlayerID,
+			Name:             r.Name,
+			SelectedOptionID: r.SelectedOptionID,
+			Correct:          r.Correct,
+			Score:            r.Score,
+		})
+	}
+	return roundResultsV1{
+		Code:            rr.Code,
+		RoundNumber:     rr.RoundNumber,
+		Question:        rr.Question,
+		Options:         rr.Options,
+		CorrectOptionID: rr.CorrectOptionID,
+		Results:         results,
+	}
+}
+
+func gameOverToV1(payload interface{}) interface{} {
+	g, ok := payload.(game.GameOverPayload)
+	if !ok {
+		return payload
+	}
+	leaderboard := make([]leaderboardEntryV1, 0, len(g.Leaderboard))
+	for _, e := range g.Leaderboard {
+		leaderboard = append(leaderboard, leaderboardEntryV1{
+			Place:    e.Place,
+			PlayerID: e.PlayerID,
+			Name:     e.Name,
+			Score:    e.Score,
+		})
+	}
+	return gameOverV1{
+		Code:         g.Code,
+		RoundsPlayed: g.RoundsPlayed,
+		Leaderboard:  leaderboard,
+	}
+}
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArtemMoroz51/FinalProject/internal/game"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	v, err := negotiateVersion(0)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolV1, v)
+
+	v, err = negotiateVersion(ProtocolV2)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolV2, v)
+
+	v, err = negotiateVersion(CurrentProtocolVersion + 5)
+	require.NoError(t, err)
+	require.Equal(t, CurrentProtocolVersion, v)
+
+	_, err = negotiateVersion(-1)
+	require.ErrorIs(t, err, ErrUnsupportedProtocol)
+}
+
+func TestAcceptedCapabilities(t *testing.T) {
+	got := acceptedCapabilities([]string{"resume", "teleport", "seq_sync"})
+	require.Equal(t, []string{"resume", "seq_sync"}, got)
+}
+
+func TestEncodeForVersion_V1KeepsLegacyShape(t *testing.T) {
+	snap := game.RoomSnapshot{
+		Code:    "ABCD",
+		Phase:   game.PhaseLobby,
+		HostID:  "p1",
+		Players: []*game.Player{{ID: "p1", Name: "Host", Avatar: "fox", Online: true}},
+		Scores:  map[string]int{"p1": 0},
+	}
+	env := Envelope{Type: "room_state", Seq: 7, Payload: snap}
+
+	b, err := encodeForVersion(env, ProtocolV1)
+	require.NoError(t, err)
+
+	var legacy map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &legacy))
+	require.NotContains(t, legacy, "seq")
+
+	payload := legacy["payload"].(map[string]interface{})
+	require.NotContains(t, payload, "settings")
+	require.NotContains(t, payload, "paused")
+	player := payload["players"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"id": "p1", "name": "Host"}, player)
+
+	b, err = encodeForVersion(env, ProtocolV2)
+	require.NoError(t, err)
+
+	var current map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &current))
+	require.EqualValues(t, 7, current["seq"])
+	require.Contains(t, current["payload"].(map[string]interface{}), "settings")
+}
+
+func TestFrames_RemainingMsStampedAtSendTime(t *testing.T) {
+	deadline := time.UnixMilli(1_000_000)
+	lobby := game.RoomSnapshot{Code: "ABCD", Phase: game.PhaseLobby, Scores: map[string]int{}}
+	snap := game.RoomSnapshot{Code: "ABCD", Phase: game.PhaseAnswering, Deadline: deadline.UnixMilli(), RemainingMs: 999, Scores: map[string]int{}}
+	hist := &roomHistory{}
+	hist.nextState(1, lobby)
+	f := newFrames(Envelope{Type: "room_state", Seq: 2, Payload: snap})
+	f.setState(hist.nextState(2, snap))
+
+	remaining := func(key frameKey, now time.Time) interface{} {
+		b, err := f.get(key, now)
+		require.NoError(t, err)
+		if key.binary {
+			b, err = msgpackToJSON(b)
+			require.NoError(t, err)
+		}
+		var env map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &env))
+		return env["payload"].(map[string]interface{})["remainingMs"]
+	}
+
+	v2 := frameKey{version: ProtocolV2}
+	require.EqualValues(t, 20000, remaining(v2, deadline.Add(-20*time.Second)))
+	require.EqualValues(t, 5000, remaining(v2, deadline.Add(-5*time.Second)))
+	require.EqualValues(t, 5000, remaining(frameKey{version: ProtocolV2, binary: true}, deadline.Add(-5*time.Second)))
+	require.EqualValues(t, 5000, remaining(frameKey{version: ProtocolV1, controller: true}, deadline.Add(-5*time.Second)))
+	require.Nil(t, remaining(v2, deadline.Add(time.Second)))
+	require.Nil(t, remaining(frameKey{version: ProtocolV1}, deadline.Add(-5*time.Second)))
+
+	v3 := frameKey{version: ProtocolV3}
+	require.EqualValues(t, 20000, remaining(v3, deadline.Add(-20*time.Second)))
+	require.EqualValues(t, 5000, remaining(frameKey{version: ProtocolV3, binary: true}, deadline.Add(-5*time.Second)))
+	require.Nil(t, remaining(v3, deadline.Add(time.Second)))
+
+	now := deadline.Add(-5 * time.Second)
+	first, err := f.get(v2, now)
+	require.NoError(t, err)
+	second, err := f.get(v2, now)
+	require.NoError(t, err)
+	require.Same(t, &first[0], &second[0])
+	require.Equal(t, 1, strings.Count(string(first), `"remainingMs"`))
+}
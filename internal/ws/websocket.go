@@ -1,17 +1,19 @@
 package ws
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/ArtemMoroz51/FinalProject/internal/game"
-	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+type admission struct {
+	player  *game.Player
+	version int
+	hs      Handshake
+	resumed bool
 }
 
 func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request, roomCode string) {
@@ -21,44 +23,180 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request, roomCode string) {
 		return
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
 
-	_ = conn.SetReadDeadline(time.Now().Add(15 * time.Second))
-	var msg clientMsg
-	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "join_room" {
-		_ = conn.WriteJSON(Envelope{Type: "error", Payload: map[string]string{"message": "expected join_room"}})
+	binary := conn.Subprotocol() == SubprotocolMsgpack
+
+	conn.SetReadLimit(h.cfg.MaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(h.cfg.JoinTimeout))
+	msg, err := readClientMsg(conn)
+	if err != nil {
+		_ = writeEnvelope(conn, binary, errorEnvelope("expected join_room"))
 		_ = conn.Close()
 		return
 	}
 
-	var jp JoinPayload
-	if err := json.Unmarshal(msg.Payload, &jp); err != nil || strings.TrimSpace(jp.Name) == "" {
-		_ = conn.WriteJSON(Envelope{Type: "error", Payload: map[string]string{"message": "invalid name"}})
+	adm, errEnv, ok := h.admit(room, msg)
+	if !ok {
+		_ = writeEnvelope(conn, binary, errEnv)
 		_ = conn.Close()
 		return
 	}
 	_ = conn.SetReadDeadline(time.Time{})
 
-	playerID := newID()
-	player := &game.Player{ID: playerID, Name: strings.TrimSpace(jp.Name)}
-	_ = room.AddPlayer(player)
+	client := h.newClient(roomCode, adm)
+	client.conn = conn
+	client.binary = binary
+
+	go client.writePump()
+	h.attach(room, client, adm)
+
+	client.readPump(room)
+}
+
+func (h *Hub) admit(room *game.Room, msg clientMsg) (admission, Envelope, bool) {
+	if msg.Type != "join_room" && msg.Type != "resume" {
+		return admission{}, errorEnvelope("expected join_room"), false
+	}
+
+	payload, err := decodeClientPayload(msg.Type, msg.Payload)
+	if err != nil {
+		var perr *PayloadError
+		if errors.As(err, &perr) {
+			return admission{}, perr.Envelope(), false
+		}
+		return admission{}, errorEnvelope("bad payload"), false
+	}
+
+	adm := admission{resumed: msg.Type == "resume"}
+	switch p := payload.(type) {
+	case *JoinPayload:
+		adm.hs = p.Handshake
+	case *ResumePayload:
+		adm.hs = p.Handshake
+	}
+	adm.version, err = negotiateVersion(adm.hs.ProtocolVersion)
+	if err != nil {
+		return admission{}, errorEnvelope(err.Error()), false
+	}
+	if !validRole(adm.hs.Role) || (adm.resumed && !isPlayerRole(adm.hs.Role)) {
+		return admission{}, Envelope{Type: "error", Payload: ErrorPayload{Message: ErrInvalidRole.Error(), Code: "invalid_role"}}, false
+	}
+
+	if adm.hs.Role == RoleDisplay {
+		jp := payload.(*JoinPayload)
+		adm.player = &game.Player{ID: newID(), Name: jp.Name}
+		if err := room.AddDisplay(adm.player.ID, jp.HostToken); err != nil {
+			return admission{}, Envelope{Type: "error", Payload: displayErrorPayload(err)}, false
+		}
+		return adm, Envelope{}, true
+	}
+	if adm.hs.Role == RoleSpectator {
+		adm.player = &game.Player{ID: newID()}
+		if err := h.svc.JoinSpectator(room, adm.player.ID); err != nil {
+			return admission{}, Envelope{Type: "error", Payload: spectatorErrorPayload(err)}, false
+		}
+		return adm, Envelope{}, true
+	}
+
+	if adm.resumed {
+		rp := payload.(*ResumePayload)
+		adm.player, err = room.ResumePlayer(rp.Token)
+		if err != nil {
+			return admission{}, errorEnvelope(err.Error()), false
+		}
+		return adm, Envelope{}, true
+	}
+
+	jp := payload.(*JoinPayload)
+	p := &game.Player{
+		ID:          newID(),
+		Name:        jp.Name,
+		Avatar:      jp.Avatar,
+		Color:       jp.Color,
+		ResumeToken: newID(),
+	}
+	if adm.player, _, err = h.svc.JoinRoom(room, p); err != nil {
+		return admission{}, Envelope{Type: "error", Payload: h.joinErrorPayload(room, p.Name, err)}, false
+	}
+	return adm, Envelope{}, true
+}
 
-	client := &Client{
+func (h *Hub) newClient(roomCode string, adm admission) *Client {
+	return &Client{
 		hub:      h,
 		roomCode: strings.ToUpper(roomCode),
-		playerID: playerID,
-		conn:     conn,
-		send:     make(chan []byte, 64),
+		playerID: adm.player.ID,
+		session:  adm.player.Session,
+		version:  adm.version,
+		role:     adm.hs.Role,
+		send:     make(chan []byte, h.cfg.SendBufferSize),
+		done:     make(chan struct{}),
+
+		reactions: newTokenBucket(reactionBurst, reactionRate),
+		limiter:   newInboundLimiter(h.cfg.RateLimits),
 	}
+}
 
+func (h *Hub) attach(room *game.Room, client *Client, adm admission) {
 	h.register <- client
-	go client.writePump()
 
-	h.Broadcast(roomCode, Envelope{Type: "player_joined", Payload: player})
-	h.Broadcast(roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+	client.sendJSON(Envelope{Type: "welcome", Payload: WelcomePayload{
+		ProtocolVersion: adm.version,
+		Deprecated:      adm.version <= DeprecatedProtocolVersion,
+		Features:        serverFeatures,
+		Capabilities:    acceptedCapabilities(adm.hs.Capabilities),
+	}})
+	client.sendJSON(Envelope{Type: "joined", Payload: JoinedPayload{
+		PlayerID:    adm.player.ID,
+		ResumeToken: adm.player.ResumeToken,
+		Role:        client.role,
+		Answer:      room.PendingAnswer(adm.player.ID),
+	}})
+	client.sendJSON(Envelope{Type: "chat_history", Payload: ChatHistoryPayload{Messages: room.ChatHistory()}})
 
-	client.readPump(room)
+	if isPlayerRole(client.role) {
+		h.recheckReadyQuorum(room, client.roomCode)
+		if adm.resumed {
+			h.Broadcast(client.roomCode, Envelope{Type: "player_resumed", Payload: adm.player})
+		} else {
+			h.Broadcast(client.roomCode, Envelope{Type: "player_joined", Payload: adm.player})
+		}
+	}
+	h.Broadcast(client.roomCode, Envelope{Type: "room_state", Payload: room.Snapshot()})
+	if adm.version >= ProtocolV3 && client.role != RoleController {
+		client.sendFullState(room)
+	}
+}
+
+func (h *Hub) joinErrorPayload(room *game.Room, name string, err error) ErrorPayload {
+	switch {
+	case errors.Is(err, game.ErrNameTaken):
+		return ErrorPayload{
+			Message:    err.Error(),
+			Code:       "name_taken",
+			Suggestion: h.svc.SuggestName(room, name),
+		}
+	case errors.Is(err, game.ErrNameTooShort), errors.Is(err, game.ErrNameTooLong), errors.Is(err, game.ErrNameInvalid):
+		return ErrorPayload{Message: err.Error(), Code: "invalid_name"}
+	default:
+		return ErrorPayload{Message: err.Error()}
+	}
+}
+
+func displayErrorPayload(err error) ErrorPayload {
+	if errors.Is(err, game.ErrBadHostToken) {
+		return ErrorPayload{Message: err.Error(), Code: "bad_host_token"}
+	}
+	return ErrorPayload{Message: err.Error()}
+}
+
+func spectatorErrorPayload(err error) ErrorPayload {
+	if errors.Is(err, game.ErrSpectatorsFull) {
+		return ErrorPayload{Message: err.Error(), Code: "spectators_full"}
+	}
+	return ErrorPayload{Message: err.Error()}
 }